@@ -48,4 +48,24 @@ const (
 	// ReconciliationFailedReason represents the fact that
 	// the reconciliation failed.
 	ReconciliationFailedReason string = "ReconciliationFailed"
+
+	// DryRunSucceededReason represents the fact that
+	// the server-side apply dry-run succeeded.
+	DryRunSucceededReason string = "DryRunSucceeded"
+
+	// OutsideScheduleReason represents the fact that
+	// applying the changes was deferred by the schedule.
+	OutsideScheduleReason string = "OutsideSchedule"
+
+	// OwnershipConflictReason represents the fact that
+	// objects are owned by another Kustomization.
+	OwnershipConflictReason string = "OwnershipConflict"
+
+	// RetryLimitReachedReason represents the fact that
+	// the reconciliation failed too many times in a row.
+	RetryLimitReachedReason string = "RetryLimitReached"
+
+	// InvalidSpecReason represents the fact that
+	// the Kustomization spec is invalid.
+	InvalidSpecReason string = "InvalidSpec"
 )
@@ -35,6 +35,33 @@ const (
 	IgnoreValue               = "Ignore"
 )
 
+const (
+	// DeletionPolicyMirrorPrune decides to delete or orphan the managed
+	// resources based on the value of the Prune field.
+	DeletionPolicyMirrorPrune = "MirrorPrune"
+
+	// DeletionPolicyDelete deletes the managed resources when the
+	// Kustomization is deleted.
+	DeletionPolicyDelete = "Delete"
+
+	// DeletionPolicyWaitForTermination deletes the managed resources and
+	// waits for them to be terminated before removing the finalizer.
+	DeletionPolicyWaitForTermination = "WaitForTermination"
+
+	// DeletionPolicyOrphan leaves the managed resources in the cluster
+	// when the Kustomization is deleted.
+	DeletionPolicyOrphan = "Orphan"
+)
+
+const (
+	// ApplyMode applies the manifests on the cluster.
+	ApplyMode = "Apply"
+
+	// DryRunMode computes the changes with a server-side apply dry-run,
+	// without applying the manifests on the cluster.
+	DryRunMode = "DryRun"
+)
+
 // KustomizationSpec defines the configuration to calculate the desired state
 // from a Source using Kustomize.
 type KustomizationSpec struct {
@@ -64,7 +91,9 @@ type KustomizationSpec struct {
 
 	// The interval at which to retry a previously failed reconciliation.
 	// When not specified, the controller uses the KustomizationSpec.Interval
-	// value to retry failures.
+	// value to retry failures. When the controller is configured with a
+	// maximum failure retry delay, the interval doubles with each consecutive
+	// failure until that delay.
 	// +kubebuilder:validation:Type=string
 	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ms|s|m|h))+$"
 	// +optional
@@ -95,6 +124,14 @@ type KustomizationSpec struct {
 	// +required
 	Prune bool `json:"prune"`
 
+	// DeletionPolicy can be used to control garbage collection when this
+	// Kustomization is deleted. Valid values are ('MirrorPrune', 'Delete',
+	// 'WaitForTermination', 'Orphan'). 'MirrorPrune' mirrors the Prune field
+	// (orphan if false, delete if true). Defaults to 'MirrorPrune'.
+	// +kubebuilder:validation:Enum=MirrorPrune;Delete;WaitForTermination;Orphan
+	// +optional
+	DeletionPolicy string `json:"deletionPolicy,omitempty"`
+
 	// A list of resources to be included in the health assessment.
 	// +optional
 	HealthChecks []meta.NamespacedObjectKindReference `json:"healthChecks,omitempty"`
@@ -138,6 +175,13 @@ type KustomizationSpec struct {
 	// +optional
 	Suspend bool `json:"suspend,omitempty"`
 
+	// Schedule restricts the time windows in which the controller is
+	// allowed to apply changes on the cluster. Outside the allowed windows,
+	// the changes are computed with a server-side apply dry-run and
+	// reported in events, without being applied.
+	// +optional
+	Schedule *Schedule `json:"schedule,omitempty"`
+
 	// TargetNamespace sets or overrides the namespace in the
 	// kustomization.yaml file.
 	// +kubebuilder:validation:MinLength=1
@@ -146,6 +190,13 @@ type KustomizationSpec struct {
 	// +optional
 	TargetNamespace string `json:"targetNamespace,omitempty"`
 
+	// CreateNamespace tells the controller to create the TargetNamespace
+	// if it doesn't exist, with the labels and annotations of the
+	// CommonMetadata. The Namespace is not modified after its creation,
+	// and is not deleted by garbage collection. Defaults to false.
+	// +optional
+	CreateNamespace bool `json:"createNamespace,omitempty"`
+
 	// Timeout for validation, apply and health checking operations.
 	// Defaults to 'Interval' duration.
 	// +kubebuilder:validation:Type=string
@@ -159,11 +210,25 @@ type KustomizationSpec struct {
 	// +optional
 	Force bool `json:"force,omitempty"`
 
+	// Ignore contains a list of rules for specifying which changes to ignore
+	// when applying the manifests and detecting drift. The ignored fields of
+	// the in-cluster objects are kept when applying the manifests.
+	// +optional
+	Ignore []IgnoreRule `json:"ignore,omitempty"`
+
 	// Wait instructs the controller to check the health of all the reconciled
 	// resources. When enabled, the HealthChecks are ignored. Defaults to false.
 	// +optional
 	Wait bool `json:"wait,omitempty"`
 
+	// Mode defines how the manifests are reconciled. 'Apply' applies the
+	// manifests on the cluster, 'DryRun' performs a server-side apply
+	// dry-run and reports the changes in events and status, without
+	// applying the manifests. Defaults to 'Apply'.
+	// +kubebuilder:validation:Enum=Apply;DryRun
+	// +optional
+	Mode string `json:"mode,omitempty"`
+
 	// Components specifies relative paths to specifications of other Components.
 	// +optional
 	Components []string `json:"components,omitempty"`
@@ -192,6 +257,21 @@ type Decryption struct {
 	SecretRef *meta.LocalObjectReference `json:"secretRef,omitempty"`
 }
 
+// IgnoreRule defines a rule to selectively disregard specific changes during
+// the apply and drift detection.
+type IgnoreRule struct {
+	// Paths is a list of JSON Pointer (RFC 6901) paths to be excluded from
+	// the diff and the apply of the objects selected by Target.
+	// +required
+	Paths []string `json:"paths"`
+
+	// Target is a selector for specifying the objects to which the ignore
+	// rule applies. If Target is not set, the Paths are ignored for all the
+	// objects.
+	// +optional
+	Target *kustomize.Selector `json:"target,omitempty"`
+}
+
 // PostBuild describes which actions to perform on the YAML manifest
 // generated by building the kustomize overlay.
 type PostBuild struct {
@@ -212,6 +292,40 @@ type PostBuild struct {
 	SubstituteFrom []SubstituteReference `json:"substituteFrom,omitempty"`
 }
 
+// Schedule defines the time windows in which changes can be applied.
+type Schedule struct {
+	// Allow is a list of windows in which changes are applied.
+	// When empty, changes are allowed at any time outside the deny windows.
+	// +optional
+	Allow []ScheduleWindow `json:"allow,omitempty"`
+
+	// Deny is a list of windows in which changes are not applied,
+	// it takes precedence over the allow windows.
+	// +optional
+	Deny []ScheduleWindow `json:"deny,omitempty"`
+
+	// TimeZone is the IANA time zone name used to evaluate the cron
+	// expressions, e.g. 'Europe/London'. Defaults to 'UTC'.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// ScheduleWindow defines a time window starting at the times matched by
+// a cron expression and lasting for the given duration.
+type ScheduleWindow struct {
+	// Cron is a standard five fields cron expression
+	// (minute, hour, day of month, month, day of week)
+	// that defines the start of the window.
+	// +required
+	Cron string `json:"cron"`
+
+	// Duration is the length of the window.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ms|s|m|h))+$"
+	// +required
+	Duration metav1.Duration `json:"duration"`
+}
+
 // SubstituteReference contains a reference to a resource containing
 // the variables name and value.
 type SubstituteReference struct {
@@ -287,6 +401,21 @@ func (in Kustomization) GetRequeueAfter() time.Duration {
 	return in.Spec.Interval.Duration
 }
 
+// GetDeletionPolicy returns the deletion policy, resolving
+// DeletionPolicyMirrorPrune to either DeletionPolicyDelete or
+// DeletionPolicyOrphan based on the value of the Prune field.
+func (in Kustomization) GetDeletionPolicy() string {
+	switch in.Spec.DeletionPolicy {
+	case "", DeletionPolicyMirrorPrune:
+		if in.Spec.Prune {
+			return DeletionPolicyDelete
+		}
+		return DeletionPolicyOrphan
+	default:
+		return in.Spec.DeletionPolicy
+	}
+}
+
 // GetDependsOn returns the list of dependencies across-namespaces.
 func (in Kustomization) GetDependsOn() []meta.NamespacedObjectReference {
 	return in.Spec.DependsOn
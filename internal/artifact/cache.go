@@ -0,0 +1,230 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package artifact
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+)
+
+// FetchFunc downloads, verifies and extracts an artifact into the given
+// directory.
+type FetchFunc func(dir string) error
+
+// Cache keeps the extracted source artifacts on disk, keyed by their digest,
+// so that the Kustomizations referring to the same revision download and
+// extract the artifact only once. A nil Cache is valid and fetches every
+// artifact.
+type Cache struct {
+	dir        string
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]*entry
+	// seq makes the entry paths unique, so that the directory of an evicted
+	// entry can be removed while a new entry for the same digest is fetched.
+	seq uint64
+}
+
+type entry struct {
+	// mu is held while the artifact is fetched.
+	mu       sync.Mutex
+	path     string
+	ready    bool
+	refs     int
+	lastUsed time.Time
+}
+
+// NewCache creates a Cache storing at most maxEntries artifacts in dir.
+// The content of dir is removed.
+func NewCache(dir string, maxEntries int) (*Cache, error) {
+	if maxEntries < 1 {
+		return nil, fmt.Errorf("invalid cache size %d, must be greater than zero", maxEntries)
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, fmt.Errorf("failed to clean cache dir: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create cache dir: %w", err)
+	}
+	return &Cache{
+		dir:        dir,
+		maxEntries: maxEntries,
+		entries:    make(map[string]*entry),
+	}, nil
+}
+
+// Get copies the files of the artifact with the given digest into dst.
+// If the artifact is not in the cache, fetch is called to add it. When the
+// digest is not valid, fetch is called with dst and nothing is cached.
+func (c *Cache) Get(artifactDigest string, dst string, fetch FetchFunc) error {
+	if c == nil {
+		return fetch(dst)
+	}
+	d, err := digest.Parse(artifactDigest)
+	if err != nil {
+		return fetch(dst)
+	}
+
+	e := c.acquire(d)
+	defer c.release(d.String(), e)
+
+	e.mu.Lock()
+	if !e.ready {
+		if err := c.fetch(e, fetch); err != nil {
+			e.mu.Unlock()
+			return err
+		}
+		e.ready = true
+	}
+	e.mu.Unlock()
+
+	return copyDir(e.path, dst)
+}
+
+// acquire returns the entry of the given digest, creating it if needed,
+// and marks it as in use.
+func (c *Cache) acquire(d digest.Digest) *entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[d.String()]
+	if !ok {
+		c.seq++
+		e = &entry{
+			path: filepath.Join(c.dir, fmt.Sprintf("%s-%s-%d", d.Algorithm(), d.Encoded(), c.seq)),
+		}
+		c.entries[d.String()] = e
+	}
+	e.refs++
+	e.lastUsed = time.Now()
+	return e
+}
+
+// release marks the entry as no longer in use, and evicts the entries
+// that failed to be fetched or that exceed the cache size. The files of
+// the evicted entries are removed after unlocking the cache.
+func (c *Cache) release(key string, e *entry) {
+	for _, path := range c.evict(key, e) {
+		_ = os.RemoveAll(path)
+	}
+}
+
+// evict releases the entry and removes the evicted entries from the cache,
+// returning their paths.
+func (c *Cache) evict(key string, e *entry) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var evicted []string
+	e.refs--
+	if e.refs == 0 && !e.ready {
+		delete(c.entries, key)
+		evicted = append(evicted, e.path)
+	}
+
+	for len(c.entries) > c.maxEntries {
+		var oldestKey string
+		var oldest *entry
+		for k, v := range c.entries {
+			if v.refs > 0 {
+				continue
+			}
+			if oldest == nil || v.lastUsed.Before(oldest.lastUsed) {
+				oldestKey, oldest = k, v
+			}
+		}
+		if oldest == nil {
+			// All the entries are in use.
+			break
+		}
+		delete(c.entries, oldestKey)
+		evicted = append(evicted, oldest.path)
+	}
+	return evicted
+}
+
+// fetch calls fn with a temporary directory which is renamed to the
+// entry path on success.
+func (c *Cache) fetch(e *entry, fn FetchFunc) error {
+	tmpDir, err := os.MkdirTemp(c.dir, "fetch-")
+	if err != nil {
+		return fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	if err := fn(tmpDir); err != nil {
+		_ = os.RemoveAll(tmpDir)
+		return err
+	}
+	_ = os.RemoveAll(e.path)
+	if err := os.Rename(tmpDir, e.path); err != nil {
+		_ = os.RemoveAll(tmpDir)
+		return fmt.Errorf("failed to store artifact in cache: %w", err)
+	}
+	return nil
+}
+
+// copyDir copies the directories and regular files from src into dst.
+func copyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm()|0o700)
+		case info.Mode().IsRegular():
+			return copyFile(path, target, info.Mode().Perm())
+		default:
+			return nil
+		}
+	})
+}
+
+func copyFile(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package artifact
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+const (
+	digestA = "sha256:2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	digestB = "sha256:486ea46224d1bb4fb680f34f7c9ad96a8f24ec88be73ea8e5a6c65260e9cb8a7"
+	digestC = "sha256:fcde2b2edba56bf408601fb721fe9b5c338d10ee429ea04fae5511b68fbf8fb9"
+)
+
+func fetchFiles(calls *int32, files map[string]string) FetchFunc {
+	return func(dir string) error {
+		atomic.AddInt32(calls, 1)
+		for name, data := range files {
+			p := filepath.Join(dir, name)
+			if err := os.MkdirAll(filepath.Dir(p), 0o700); err != nil {
+				return err
+			}
+			if err := os.WriteFile(p, []byte(data), 0o600); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func TestCache_Get(t *testing.T) {
+	g := NewWithT(t)
+
+	c, err := NewCache(filepath.Join(t.TempDir(), "cache"), 2)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	var calls int32
+	fetch := fetchFiles(&calls, map[string]string{
+		"kustomization.yaml": "resources: []",
+		"apps/app.yaml":      "kind: ConfigMap",
+	})
+
+	for i := 0; i < 3; i++ {
+		dst := t.TempDir()
+		g.Expect(c.Get(digestA, dst, fetch)).To(Succeed())
+		data, err := os.ReadFile(filepath.Join(dst, "apps", "app.yaml"))
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(string(data)).To(Equal("kind: ConfigMap"))
+	}
+	g.Expect(calls).To(BeEquivalentTo(1))
+
+	// Changes in the destination must not alter the cached files.
+	dst := t.TempDir()
+	g.Expect(c.Get(digestA, dst, fetch)).To(Succeed())
+	g.Expect(os.WriteFile(filepath.Join(dst, "kustomization.yaml"), []byte("changed"), 0o600)).To(Succeed())
+	dst = t.TempDir()
+	g.Expect(c.Get(digestA, dst, fetch)).To(Succeed())
+	data, err := os.ReadFile(filepath.Join(dst, "kustomization.yaml"))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(data)).To(Equal("resources: []"))
+	g.Expect(calls).To(BeEquivalentTo(1))
+}
+
+func TestCache_GetConcurrent(t *testing.T) {
+	g := NewWithT(t)
+
+	c, err := NewCache(filepath.Join(t.TempDir(), "cache"), 1)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	var calls int32
+	fetch := fetchFiles(&calls, map[string]string{"app.yaml": "kind: ConfigMap"})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		dst := t.TempDir()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- c.Get(digestA, dst, fetch)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		g.Expect(err).NotTo(HaveOccurred())
+	}
+	g.Expect(calls).To(BeEquivalentTo(1))
+}
+
+func TestCache_Evict(t *testing.T) {
+	g := NewWithT(t)
+
+	c, err := NewCache(filepath.Join(t.TempDir(), "cache"), 2)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	var calls int32
+	fetch := fetchFiles(&calls, map[string]string{"app.yaml": "kind: ConfigMap"})
+
+	for _, d := range []string{digestA, digestB, digestC} {
+		g.Expect(c.Get(d, t.TempDir(), fetch)).To(Succeed())
+	}
+	g.Expect(calls).To(BeEquivalentTo(3))
+	g.Expect(c.entries).To(HaveLen(2))
+	g.Expect(c.entries).NotTo(HaveKey(digestA))
+
+	entries, err := os.ReadDir(c.dir)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(entries).To(HaveLen(2))
+
+	g.Expect(c.Get(digestA, t.TempDir(), fetch)).To(Succeed())
+	g.Expect(calls).To(BeEquivalentTo(4))
+}
+
+func TestCache_GetError(t *testing.T) {
+	g := NewWithT(t)
+
+	c, err := NewCache(filepath.Join(t.TempDir(), "cache"), 2)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	fetchErr := errors.New("download failed")
+	err = c.Get(digestA, t.TempDir(), func(dir string) error {
+		return fetchErr
+	})
+	g.Expect(err).To(MatchError(fetchErr))
+	g.Expect(c.entries).To(BeEmpty())
+
+	entries, err := os.ReadDir(c.dir)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(entries).To(BeEmpty())
+}
+
+func TestCache_GetWithoutCache(t *testing.T) {
+	g := NewWithT(t)
+
+	var calls int32
+	fetch := fetchFiles(&calls, map[string]string{"app.yaml": "kind: ConfigMap"})
+
+	var nilCache *Cache
+	g.Expect(nilCache.Get(digestA, t.TempDir(), fetch)).To(Succeed())
+
+	c, err := NewCache(filepath.Join(t.TempDir(), "cache"), 2)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(c.Get("", t.TempDir(), fetch)).To(Succeed())
+	g.Expect(c.Get("", t.TempDir(), fetch)).To(Succeed())
+	g.Expect(c.entries).To(BeEmpty())
+
+	g.Expect(calls).To(BeEquivalentTo(3))
+}
@@ -24,6 +24,7 @@ import (
 
 	apiacl "github.com/fluxcd/pkg/apis/acl"
 	"github.com/fluxcd/pkg/apis/meta"
+	"github.com/fluxcd/pkg/runtime/acl"
 	"github.com/fluxcd/pkg/testserver"
 	sourcev1 "github.com/fluxcd/source-controller/api/v1"
 	. "github.com/onsi/gomega"
@@ -134,3 +135,30 @@ stringData:
 		g.Expect(readyCondition.Reason).To(Equal(apiacl.AccessDeniedReason))
 	})
 }
+
+func TestKustomizationReconciler_checkDependencies_NoCrossNamespaceDependencies(t *testing.T) {
+	g := NewWithT(t)
+
+	r := &KustomizationReconciler{
+		NoCrossNamespaceDeps: true,
+	}
+	obj := &kustomizev1.Kustomization{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "apps",
+			Namespace: "apps",
+		},
+		Spec: kustomizev1.KustomizationSpec{
+			DependsOn: []meta.NamespacedObjectReference{
+				{
+					Name:      "infra",
+					Namespace: "flux-system",
+				},
+			},
+		},
+	}
+
+	err := r.checkDependencies(context.Background(), obj, nil)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(acl.IsAccessDenied(err)).To(BeTrue())
+	g.Expect(err.Error()).To(ContainSubstring("flux-system/infra"))
+}
@@ -24,6 +24,7 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	securejoin "github.com/cyphar/filepath-securejoin"
@@ -35,6 +36,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	kuberecorder "k8s.io/client-go/tools/record"
@@ -67,8 +69,11 @@ import (
 	sourcev1b2 "github.com/fluxcd/source-controller/api/v1beta2"
 
 	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1"
+	"github.com/fluxcd/kustomize-controller/internal/artifact"
 	"github.com/fluxcd/kustomize-controller/internal/decryptor"
 	"github.com/fluxcd/kustomize-controller/internal/inventory"
+	kmetrics "github.com/fluxcd/kustomize-controller/internal/metrics"
+	"github.com/fluxcd/kustomize-controller/internal/schedule"
 )
 
 // +kubebuilder:rbac:groups=kustomize.toolkit.fluxcd.io,resources=kustomizations,verbs=get;list;watch;create;update;patch;delete
@@ -79,27 +84,48 @@ import (
 // +kubebuilder:rbac:groups="",resources=configmaps;secrets;serviceaccounts,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
+// statusPatchTimeout is the timeout for recording the status of a
+// reconciliation aborted on shutdown.
+const statusPatchTimeout = 10 * time.Second
+
 // KustomizationReconciler reconciles a Kustomization object
 type KustomizationReconciler struct {
 	client.Client
+	// APIReader reads objects from the API server without using the cache
+	// of the Client, to avoid starting informers for arbitrary kinds.
+	APIReader client.Reader
 	kuberecorder.EventRecorder
 	runtimeCtrl.Metrics
+	PhaseMetrics *kmetrics.Recorder
+	// ArtifactCache shares the extracted artifacts between the
+	// Kustomizations referring to the same source revision.
+	ArtifactCache *artifact.Cache
 
 	artifactFetchRetries int
+	maxDownloadSize      int
+	maxUntarSize         int
 	requeueDependency    time.Duration
-
-	StatusPoller            *polling.StatusPoller
-	PollingOpts             polling.Options
-	ControllerName          string
-	statusManager           string
-	NoCrossNamespaceRefs    bool
-	NoRemoteBases           bool
-	FailFast                bool
-	DefaultServiceAccount   string
-	KubeConfigOpts          runtimeClient.KubeConfigOptions
-	ConcurrentSSA           int
-	DisallowedFieldManagers []string
-	StrictSubstitutions     bool
+	maxRetryDelay        time.Duration
+	failures             sync.Map
+	maxFailures          int
+	skippedNamespaces    sync.Map
+
+	StatusPoller             *polling.StatusPoller
+	PollingOpts              polling.Options
+	ControllerName           string
+	FieldManager             string
+	statusManager            string
+	NoCrossNamespaceRefs     bool
+	NoCrossNamespaceDeps     bool
+	NoRemoteBases            bool
+	FailFast                 bool
+	DefaultServiceAccount    string
+	KubeConfigOpts           runtimeClient.KubeConfigOptions
+	ConcurrentSSA            int
+	DisallowedFieldManagers  []string
+	StrictSubstitutions      bool
+	KMSOnlyDecryption        bool
+	DetectOwnershipConflicts bool
 }
 
 // KustomizationReconcilerOptions contains options for the KustomizationReconciler.
@@ -107,6 +133,20 @@ type KustomizationReconcilerOptions struct {
 	HTTPRetry                 int
 	DependencyRequeueInterval time.Duration
 	RateLimiter               ratelimiter.RateLimiter
+	// MaxFailureRetryDelay enables the exponential backoff of consecutive
+	// failed reconciliations for each object. The delay starts at the
+	// object's retry interval and doubles with each failure until this
+	// value. Zero disables the backoff.
+	MaxFailureRetryDelay time.Duration
+	// MaxConsecutiveFailures is the number of consecutive failed
+	// reconciliations after which an object is marked as stalled and
+	// retried at its interval. Zero disables the limit.
+	MaxConsecutiveFailures int
+	// ArtifactMaxDownloadSize and ArtifactMaxUntarSize are the maximum
+	// sizes in bytes of the downloaded artifacts and of their extracted
+	// files. Zero disables the limits.
+	ArtifactMaxDownloadSize int
+	ArtifactMaxUntarSize    int
 }
 
 func (r *KustomizationReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, opts KustomizationReconcilerOptions) error {
@@ -136,7 +176,20 @@ func (r *KustomizationReconciler) SetupWithManager(ctx context.Context, mgr ctrl
 
 	r.requeueDependency = opts.DependencyRequeueInterval
 	r.statusManager = fmt.Sprintf("gotk-%s", r.ControllerName)
+	if r.FieldManager == "" {
+		r.FieldManager = r.ControllerName
+	}
 	r.artifactFetchRetries = opts.HTTPRetry
+	r.maxDownloadSize = tar.UnlimitedUntarSize
+	if opts.ArtifactMaxDownloadSize > 0 {
+		r.maxDownloadSize = opts.ArtifactMaxDownloadSize
+	}
+	r.maxUntarSize = tar.UnlimitedUntarSize
+	if opts.ArtifactMaxUntarSize > 0 {
+		r.maxUntarSize = opts.ArtifactMaxUntarSize
+	}
+	r.maxRetryDelay = opts.MaxFailureRetryDelay
+	r.maxFailures = opts.MaxConsecutiveFailures
 
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&kustomizev1.Kustomization{}, builder.WithPredicates(
@@ -177,8 +230,16 @@ func (r *KustomizationReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 
 	// Finalise the reconciliation and report the results.
 	defer func() {
-		// Patch finalizers, status and conditions.
-		if err := r.finalizeStatus(ctx, obj, patcher); err != nil {
+		// Patch finalizers, status and conditions. On shutdown, the context
+		// is cancelled while the reconciliation is still in progress; detach
+		// the patch from the cancellation to record the aborted reconciliation.
+		patchCtx := ctx
+		if ctx.Err() != nil {
+			var cancel context.CancelFunc
+			patchCtx, cancel = context.WithTimeout(context.WithoutCancel(ctx), statusPatchTimeout)
+			defer cancel()
+		}
+		if err := r.finalizeStatus(patchCtx, obj, patcher); err != nil {
 			retErr = kerrors.NewAggregate([]error{retErr, err})
 		}
 
@@ -187,21 +248,28 @@ func (r *KustomizationReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		r.Metrics.RecordDuration(ctx, obj, reconcileStart)
 		r.Metrics.RecordSuspend(ctx, obj, obj.Spec.Suspend)
 
-		// Log and emit success event.
+		// Log and emit success event. The commit status is not updated
+		// if the changes were not applied, in dry-run mode or outside
+		// of the schedule windows.
 		if conditions.IsReady(obj) {
 			msg := fmt.Sprintf("Reconciliation finished in %s, next run in %s",
 				time.Since(reconcileStart).String(),
 				obj.Spec.Interval.Duration.String())
 			log.Info(msg, "revision", obj.Status.LastAttemptedRevision)
-			r.event(obj, obj.Status.LastAppliedRevision, eventv1.EventSeverityInfo, msg,
-				map[string]string{
-					kustomizev1.GroupVersion.Group + "/" + eventv1.MetaCommitStatusKey: eventv1.MetaCommitStatusUpdateValue,
-				})
+			switch conditions.GetReason(obj, meta.ReadyCondition) {
+			case kustomizev1.DryRunSucceededReason, kustomizev1.OutsideScheduleReason:
+			default:
+				r.event(obj, obj.Status.LastAppliedRevision, eventv1.EventSeverityInfo, msg,
+					map[string]string{
+						kustomizev1.GroupVersion.Group + "/" + eventv1.MetaCommitStatusKey: eventv1.MetaCommitStatusUpdateValue,
+					})
+			}
 		}
 	}()
 
 	// Prune managed resources if the object is under deletion.
 	if !obj.ObjectMeta.DeletionTimestamp.IsZero() {
+		r.resetRetryInterval(obj)
 		return r.finalize(ctx, obj)
 	}
 
@@ -220,6 +288,20 @@ func (r *KustomizationReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		return ctrl.Result{}, nil
 	}
 
+	// Retry a changed spec without the backoff of its previous failures.
+	r.resetRetryIntervalOnChange(obj)
+
+	// Stall the reconciliation if the spec is invalid, as retrying
+	// can only succeed after the object is updated.
+	if err := r.validateSpec(obj); err != nil {
+		conditions.MarkStalled(obj, kustomizev1.InvalidSpecReason, err.Error())
+		conditions.MarkFalse(obj, meta.ReadyCondition, kustomizev1.InvalidSpecReason, err.Error())
+		log.Error(err, "Reconciliation stalled")
+		r.event(obj, "", eventv1.EventSeverityError, err.Error(), nil)
+		return ctrl.Result{}, nil
+	}
+	conditions.Delete(obj, meta.StalledCondition)
+
 	// Resolve the source reference and requeue the reconciliation if the source is not found.
 	artifactSource, err := r.getSource(ctx, obj)
 	if err != nil {
@@ -253,6 +335,13 @@ func (r *KustomizationReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	// Check dependencies and requeue the reconciliation if the check fails.
 	if len(obj.Spec.DependsOn) > 0 {
 		if err := r.checkDependencies(ctx, obj, artifactSource); err != nil {
+			if acl.IsAccessDenied(err) {
+				conditions.MarkFalse(obj, meta.ReadyCondition, apiacl.AccessDeniedReason, err.Error())
+				log.Error(err, "Access denied to cross-namespace dependency")
+				r.event(obj, artifactSource.GetArtifact().Revision, eventv1.EventSeverityError, err.Error(), nil)
+				return ctrl.Result{RequeueAfter: obj.GetRetryInterval()}, nil
+			}
+
 			conditions.MarkFalse(obj, meta.ReadyCondition, kustomizev1.DependencyNotReadyReason, err.Error())
 			msg := fmt.Sprintf("Dependencies do not meet ready condition, retrying in %s", r.requeueDependency.String())
 			log.Info(msg)
@@ -273,20 +362,50 @@ func (r *KustomizationReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		return ctrl.Result{RequeueAfter: r.requeueDependency}, nil
 	}
 
-	// Broadcast the reconciliation failure and requeue at the specified retry interval.
+	// Broadcast the reconciliation failure and requeue with backoff.
 	if reconcileErr != nil {
+		retryInterval := r.getRetryInterval(obj)
+
+		// Stop the fast retries once the object has failed too many times in a row.
+		failures := r.getFailures(obj)
+		stalled := r.maxFailures > 0 && failures >= r.maxFailures
+		if stalled {
+			retryInterval = obj.GetRequeueAfter()
+		}
+
 		log.Error(reconcileErr, fmt.Sprintf("Reconciliation failed after %s, next try in %s",
 			time.Since(reconcileStart).String(),
-			obj.GetRetryInterval().String()),
+			retryInterval.String()),
 			"revision",
 			artifactSource.GetArtifact().Revision)
 		r.event(obj, artifactSource.GetArtifact().Revision, eventv1.EventSeverityError,
 			reconcileErr.Error(), nil)
-		return ctrl.Result{RequeueAfter: obj.GetRetryInterval()}, nil
+
+		if stalled {
+			msg := fmt.Sprintf("Reconciliation stalled after %d consecutive failures, next try in %s",
+				failures, retryInterval.String())
+			conditions.MarkStalled(obj, kustomizev1.RetryLimitReachedReason, msg)
+			// Emit the event only when the limit is reached.
+			if failures == r.maxFailures {
+				r.event(obj, artifactSource.GetArtifact().Revision, eventv1.EventSeverityError, msg, nil)
+			}
+		}
+		return ctrl.Result{RequeueAfter: retryInterval}, nil
 	}
 
-	// Requeue the reconciliation at the specified interval.
-	return ctrl.Result{RequeueAfter: jitter.JitteredIntervalDuration(obj.GetRequeueAfter())}, nil
+	// Reset the failure backoff and requeue the reconciliation at the specified interval.
+	r.resetRetryInterval(obj)
+	requeueAfter := jitter.JitteredIntervalDuration(obj.GetRequeueAfter())
+
+	// Requeue the deferred apply when the schedule opens, if that happens
+	// before the next interval.
+	if conditions.GetReason(obj, meta.ReadyCondition) == kustomizev1.OutsideScheduleReason {
+		now := time.Now()
+		if next, ok, _ := schedule.NextOpen(obj.Spec.Schedule, now, requeueAfter); ok {
+			requeueAfter = next.Sub(now)
+		}
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
 }
 
 func (r *KustomizationReconciler) reconcile(
@@ -326,13 +445,18 @@ func (r *KustomizationReconciler) reconcile(
 	}(tmpDir)
 
 	// Download artifact and extract files to the tmp dir.
-	if err = fetch.NewArchiveFetcherWithLogger(
-		r.artifactFetchRetries,
-		tar.UnlimitedUntarSize,
-		tar.UnlimitedUntarSize,
-		os.Getenv("SOURCE_CONTROLLER_LOCALHOST"),
-		ctrl.LoggerFrom(ctx),
-	).Fetch(src.GetArtifact().URL, src.GetArtifact().Digest, tmpDir); err != nil {
+	fetchStart := time.Now()
+	err = r.ArtifactCache.Get(src.GetArtifact().Digest, tmpDir, func(dir string) error {
+		return fetch.NewArchiveFetcherWithLogger(
+			r.artifactFetchRetries,
+			r.maxDownloadSize,
+			r.maxUntarSize,
+			os.Getenv("SOURCE_CONTROLLER_LOCALHOST"),
+			ctrl.LoggerFrom(ctx),
+		).Fetch(src.GetArtifact().URL, src.GetArtifact().Digest, dir)
+	})
+	r.PhaseMetrics.RecordDuration(obj.GetName(), obj.GetNamespace(), kmetrics.FetchPhase, fetchStart)
+	if err != nil {
 		conditions.MarkFalse(obj, meta.ReadyCondition, kustomizev1.ArtifactFailedReason, err.Error())
 		return err
 	}
@@ -378,6 +502,7 @@ func (r *KustomizationReconciler) reconcile(
 	}
 
 	// Generate kustomization.yaml if needed.
+	buildStart := time.Now()
 	k, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
 	if err != nil {
 		conditions.MarkFalse(obj, meta.ReadyCondition, kustomizev1.BuildFailedReason, err.Error())
@@ -391,6 +516,7 @@ func (r *KustomizationReconciler) reconcile(
 
 	// Build the Kustomize overlay and decrypt secrets if needed.
 	resources, err := r.build(ctx, obj, unstructured.Unstructured{Object: k}, tmpDir, dirPath)
+	r.PhaseMetrics.RecordDuration(obj.GetName(), obj.GetNamespace(), kmetrics.BuildPhase, buildStart)
 	if err != nil {
 		conditions.MarkFalse(obj, meta.ReadyCondition, kustomizev1.BuildFailedReason, err.Error())
 		return err
@@ -403,14 +529,66 @@ func (r *KustomizationReconciler) reconcile(
 		return err
 	}
 
+	// Add the target namespace to the objects if it should be created.
+	if obj.Spec.CreateNamespace && obj.Spec.TargetNamespace != "" {
+		objects = withTargetNamespace(objects, obj.Spec.TargetNamespace)
+	}
+
 	// Create the server-side apply manager.
 	resourceManager := ssa.NewResourceManager(kubeClient, statusPoller, ssa.Owner{
-		Field: r.ControllerName,
+		Field: r.FieldManager,
 		Group: kustomizev1.GroupVersion.Group,
 	})
 	resourceManager.SetOwnerLabels(objects, obj.GetName(), obj.GetNamespace())
 	resourceManager.SetConcurrency(r.ConcurrentSSA)
 
+	// Refuse to apply objects owned by other Kustomizations.
+	if r.DetectOwnershipConflicts {
+		if err := r.checkOwnership(ctx, resourceManager, obj, objects); err != nil {
+			conditions.MarkFalse(obj, meta.ReadyCondition, kustomizev1.OwnershipConflictReason, err.Error())
+			return err
+		}
+	}
+
+	// Defer applying the changes if outside of the schedule windows.
+	// The schedule has been validated at the start of the reconciliation.
+	scheduleOpen, _ := schedule.IsOpen(obj.Spec.Schedule, time.Now())
+
+	// Report the changes without applying them if the dry-run mode is enabled
+	// or if the schedule doesn't allow applying changes at this time.
+	if obj.Spec.Mode == kustomizev1.DryRunMode || !scheduleOpen {
+		changeSet, err := r.dryRun(ctx, resourceManager, obj, objects, oldInventory)
+		if err != nil {
+			conditions.MarkFalse(obj, meta.ReadyCondition, kustomizev1.ReconciliationFailedReason, err.Error())
+			return err
+		}
+
+		var changes []string
+		for _, change := range changeSet.Entries {
+			if HasChanged(change.Action) {
+				changes = append(changes, change.String())
+			}
+		}
+		if len(changes) > 0 {
+			r.event(obj, revision, eventv1.EventSeverityInfo,
+				fmt.Sprintf("Dry-run detected changes:\n%s", strings.Join(changes, "\n")), nil)
+		}
+
+		if !scheduleOpen && obj.Spec.Mode != kustomizev1.DryRunMode {
+			conditions.MarkTrue(obj,
+				meta.ReadyCondition,
+				kustomizev1.OutsideScheduleReason,
+				fmt.Sprintf("Outside of the schedule windows, deferred applying revision %s with %d change(s)", revision, len(changes)))
+			return nil
+		}
+
+		conditions.MarkTrue(obj,
+			meta.ReadyCondition,
+			kustomizev1.DryRunSucceededReason,
+			fmt.Sprintf("Dry-run completed for revision %s with %d change(s)", revision, len(changes)))
+		return nil
+	}
+
 	// Update status with the reconciliation progress.
 	progressingMsg = fmt.Sprintf("Detecting drift for revision %s with a timeout of %s", revision, obj.GetTimeout().String())
 	conditions.MarkReconciling(obj, meta.ProgressingReason, progressingMsg)
@@ -419,7 +597,9 @@ func (r *KustomizationReconciler) reconcile(
 	}
 
 	// Validate and apply resources in stages.
+	applyStart := time.Now()
 	drifted, changeSet, err := r.apply(ctx, resourceManager, obj, revision, objects)
+	r.PhaseMetrics.RecordDuration(obj.GetName(), obj.GetNamespace(), kmetrics.ApplyPhase, applyStart)
 	if err != nil {
 		conditions.MarkFalse(obj, meta.ReadyCondition, kustomizev1.ReconciliationFailedReason, err.Error())
 		return err
@@ -435,6 +615,7 @@ func (r *KustomizationReconciler) reconcile(
 
 	// Set last applied inventory in status.
 	obj.Status.Inventory = newInventory
+	r.PhaseMetrics.RecordApplied(obj.GetName(), obj.GetNamespace(), changeSet)
 
 	// Detect stale resources which are subject to garbage collection.
 	staleObjects, err := inventory.Diff(oldInventory, newInventory)
@@ -444,21 +625,27 @@ func (r *KustomizationReconciler) reconcile(
 	}
 
 	// Run garbage collection for stale resources that do not have pruning disabled.
-	if _, err := r.prune(ctx, resourceManager, obj, revision, staleObjects); err != nil {
+	pruneStart := time.Now()
+	_, err = r.prune(ctx, resourceManager, obj, revision, staleObjects)
+	r.PhaseMetrics.RecordDuration(obj.GetName(), obj.GetNamespace(), kmetrics.PrunePhase, pruneStart)
+	if err != nil {
 		conditions.MarkFalse(obj, meta.ReadyCondition, kustomizev1.PruneFailedReason, err.Error())
 		return err
 	}
 
 	// Run the health checks for the last applied resources.
 	isNewRevision := !src.GetArtifact().HasRevision(obj.Status.LastAppliedRevision)
-	if err := r.checkHealth(ctx,
+	healthStart := time.Now()
+	err = r.checkHealth(ctx,
 		resourceManager,
 		patcher,
 		obj,
 		revision,
 		isNewRevision,
 		drifted,
-		changeSet.ToObjMetadataSet()); err != nil {
+		changeSet.ToObjMetadataSet())
+	r.PhaseMetrics.RecordDuration(obj.GetName(), obj.GetNamespace(), kmetrics.HealthPhase, healthStart)
+	if err != nil {
 		conditions.MarkFalse(obj, meta.ReadyCondition, kustomizev1.HealthCheckFailedReason, err.Error())
 		return err
 	}
@@ -475,6 +662,33 @@ func (r *KustomizationReconciler) reconcile(
 	return nil
 }
 
+// validateSpec checks the Kustomization spec for errors that are not
+// covered by the CRD OpenAPI schema.
+func (r *KustomizationReconciler) validateSpec(obj *kustomizev1.Kustomization) error {
+	for _, d := range obj.Spec.DependsOn {
+		namespace := d.Namespace
+		if namespace == "" {
+			namespace = obj.GetNamespace()
+		}
+		if d.Name == obj.GetName() && namespace == obj.GetNamespace() {
+			return fmt.Errorf("invalid dependency '%s/%s', a Kustomization cannot depend on itself",
+				namespace, d.Name)
+		}
+	}
+
+	if err := schedule.Validate(obj.Spec.Schedule); err != nil {
+		return fmt.Errorf("invalid schedule: %w", err)
+	}
+
+	for _, rule := range obj.Spec.Ignore {
+		if err := validateIgnoreRule(rule); err != nil {
+			return fmt.Errorf("invalid ignore rule: %w", err)
+		}
+	}
+
+	return nil
+}
+
 func (r *KustomizationReconciler) checkDependencies(ctx context.Context,
 	obj *kustomizev1.Kustomization,
 	source sourcev1.Source) error {
@@ -486,6 +700,12 @@ func (r *KustomizationReconciler) checkDependencies(ctx context.Context,
 			Namespace: d.Namespace,
 			Name:      d.Name,
 		}
+
+		if r.NoCrossNamespaceDeps && d.Namespace != obj.GetNamespace() {
+			return acl.AccessDeniedError(
+				fmt.Sprintf("can't access dependency '%s', cross-namespace dependencies have been blocked", dName))
+		}
+
 		var k kustomizev1.Kustomization
 		err := r.Get(ctx, dName, &k)
 		if err != nil {
@@ -500,6 +720,13 @@ func (r *KustomizationReconciler) checkDependencies(ctx context.Context,
 			return fmt.Errorf("dependency '%s' is not ready", dName)
 		}
 
+		// The changes of dependencies in dry-run mode or outside of their
+		// schedule are not applied.
+		switch reason := conditions.GetReason(&k, meta.ReadyCondition); reason {
+		case kustomizev1.DryRunSucceededReason, kustomizev1.OutsideScheduleReason:
+			return fmt.Errorf("dependency '%s' is not ready, its changes are not applied (%s)", dName, reason)
+		}
+
 		srcNamespace := k.Spec.SourceRef.Namespace
 		if srcNamespace == "" {
 			srcNamespace = k.GetNamespace()
@@ -585,7 +812,8 @@ func (r *KustomizationReconciler) generate(obj unstructured.Unstructured,
 func (r *KustomizationReconciler) build(ctx context.Context,
 	obj *kustomizev1.Kustomization, u unstructured.Unstructured,
 	workDir, dirPath string) ([]byte, error) {
-	dec, cleanup, err := decryptor.NewTempDecryptor(workDir, r.Client, obj)
+	dec, cleanup, err := decryptor.NewTempDecryptor(workDir, r.Client, obj,
+		decryptor.WithKMSOnly(r.KMSOnlyDecryption))
 	if err != nil {
 		return nil, err
 	}
@@ -652,34 +880,9 @@ func (r *KustomizationReconciler) build(ctx context.Context,
 	return resources, nil
 }
 
-func (r *KustomizationReconciler) apply(ctx context.Context,
-	manager *ssa.ResourceManager,
-	obj *kustomizev1.Kustomization,
-	revision string,
-	objects []*unstructured.Unstructured) (bool, *ssa.ChangeSet, error) {
-	log := ctrl.LoggerFrom(ctx)
-
-	if err := normalize.UnstructuredList(objects); err != nil {
-		return false, nil, err
-	}
-
-	if cmeta := obj.Spec.CommonMetadata; cmeta != nil {
-		ssautil.SetCommonMetadata(objects, cmeta.Labels, cmeta.Annotations)
-	}
-
-	applyOpts := ssa.DefaultApplyOptions()
-	applyOpts.Force = obj.Spec.Force
-	applyOpts.ExclusionSelector = map[string]string{
-		fmt.Sprintf("%s/reconcile", kustomizev1.GroupVersion.Group): kustomizev1.DisabledValue,
-		fmt.Sprintf("%s/ssa", kustomizev1.GroupVersion.Group):       kustomizev1.IgnoreValue,
-	}
-	applyOpts.IfNotPresentSelector = map[string]string{
-		fmt.Sprintf("%s/ssa", kustomizev1.GroupVersion.Group): kustomizev1.IfNotPresentValue,
-	}
-	applyOpts.ForceSelector = map[string]string{
-		fmt.Sprintf("%s/force", kustomizev1.GroupVersion.Group): kustomizev1.EnabledValue,
-	}
-
+// takeoverFieldManagers returns the field managers whose fields are taken
+// over by the controller when applying the objects.
+func (r *KustomizationReconciler) takeoverFieldManagers() []ssa.FieldManager {
 	fieldManagers := []ssa.FieldManager{
 		{
 			// to undo changes made with 'kubectl apply --server-side --force-conflicts'
@@ -703,6 +906,14 @@ func (r *KustomizationReconciler) apply(ctx context.Context,
 		},
 	}
 
+	if r.FieldManager != r.ControllerName {
+		// to hand over the fields applied with the default field manager
+		fieldManagers = append(fieldManagers, ssa.FieldManager{
+			Name:          r.ControllerName,
+			OperationType: metav1.ManagedFieldsOperationApply,
+		})
+	}
+
 	for _, fieldManager := range r.DisallowedFieldManagers {
 		fieldManagers = append(fieldManagers, ssa.FieldManager{
 			Name:          fieldManager,
@@ -715,6 +926,185 @@ func (r *KustomizationReconciler) apply(ctx context.Context,
 		})
 	}
 
+	return fieldManagers
+}
+
+// checkOwnership returns an error listing the given objects which exist in
+// the cluster with the owner labels of another Kustomization, if that
+// Kustomization still has them in its inventory, or with fields applied by
+// another field manager which the controller would apply too. The objects
+// excluded from the apply, or applied only if not present, are not checked.
+func (r *KustomizationReconciler) checkOwnership(ctx context.Context,
+	manager *ssa.ResourceManager,
+	obj *kustomizev1.Kustomization,
+	objects []*unstructured.Unstructured) error {
+	nameLabel := fmt.Sprintf("%s/name", kustomizev1.GroupVersion.Group)
+	namespaceLabel := fmt.Sprintf("%s/namespace", kustomizev1.GroupVersion.Group)
+	skipSelector := map[string]string{
+		fmt.Sprintf("%s/reconcile", kustomizev1.GroupVersion.Group): kustomizev1.DisabledValue,
+		fmt.Sprintf("%s/ssa", kustomizev1.GroupVersion.Group):       kustomizev1.IgnoreValue,
+	}
+	ifNotPresentSelector := map[string]string{
+		fmt.Sprintf("%s/ssa", kustomizev1.GroupVersion.Group): kustomizev1.IfNotPresentValue,
+	}
+
+	reader := r.uncachedReader(obj, manager)
+	var conflicts []string
+	for _, o := range objects {
+		if ssautil.AnyInMetadata(o, skipSelector) || ssautil.AnyInMetadata(o, ifNotPresentSelector) {
+			continue
+		}
+
+		existing := &metav1.PartialObjectMetadata{}
+		existing.SetGroupVersionKind(o.GroupVersionKind())
+		if err := reader.Get(ctx, client.ObjectKeyFromObject(o), existing); err != nil {
+			if apierrors.IsNotFound(err) || apimeta.IsNoMatchError(err) {
+				continue
+			}
+			return fmt.Errorf("failed to get %s: %w", ssautil.FmtUnstructured(o), err)
+		}
+
+		foreignManagers, err := r.foreignFieldManagers(ctx, manager, o, existing)
+		if err != nil {
+			return err
+		}
+		for _, fieldManager := range foreignManagers {
+			conflicts = append(conflicts, fmt.Sprintf("%s has fields applied by field manager '%s'",
+				ssautil.FmtUnstructured(o), fieldManager))
+		}
+
+		labels := existing.GetLabels()
+		name, ok := labels[nameLabel]
+		if !ok {
+			continue
+		}
+		if name == obj.GetName() && labels[namespaceLabel] == obj.GetNamespace() {
+			continue
+		}
+
+		// Objects orphaned by a deleted Kustomization, or removed from the
+		// source of another one, keep their owner labels and can be adopted.
+		owner := &kustomizev1.Kustomization{}
+		ownerKey := types.NamespacedName{Namespace: labels[namespaceLabel], Name: name}
+		if err := r.Get(ctx, ownerKey, owner); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("failed to get Kustomization '%s': %w", ownerKey, err)
+		}
+		if !inventoryContains(owner.Status.Inventory, o) {
+			continue
+		}
+
+		conflicts = append(conflicts, fmt.Sprintf("%s is owned by Kustomization '%s'",
+			ssautil.FmtUnstructured(o), ownerKey))
+	}
+
+	if len(conflicts) > 0 {
+		return fmt.Errorf("ownership conflict:\n%s", strings.Join(conflicts, "\n"))
+	}
+	return nil
+}
+
+// foreignFieldManagers returns the managers, other than the controller and
+// the ones it takes over, which applied fields of the existing object that
+// the controller would apply too. Forcing the apply of these fields would
+// result in the controller and the other manager reverting each other's
+// changes. The changes made with update operations, such as kubectl edit,
+// are drift corrected by design and are not reported.
+func (r *KustomizationReconciler) foreignFieldManagers(ctx context.Context,
+	manager *ssa.ResourceManager,
+	desired *unstructured.Unstructured,
+	existing *metav1.PartialObjectMetadata) ([]string, error) {
+	takeover := make(map[string]bool)
+	for _, m := range r.takeoverFieldManagers() {
+		if m.OperationType == metav1.ManagedFieldsOperationApply {
+			takeover[m.Name] = true
+		}
+	}
+	var candidates []metav1.ManagedFieldsEntry
+	for _, entry := range existing.GetManagedFields() {
+		if entry.Operation != metav1.ManagedFieldsOperationApply ||
+			entry.Subresource != "" ||
+			entry.Manager == r.FieldManager ||
+			takeover[entry.Manager] ||
+			entry.FieldsV1 == nil {
+			continue
+		}
+		candidates = append(candidates, entry)
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	// Compute the fields that the controller applies with a dry-run. The
+	// objects failing the dry-run are left to the apply, which reports the
+	// error or recreates the object if forced.
+	dryRunObject := desired.DeepCopy()
+	if err := manager.Client().Patch(ctx, dryRunObject, client.Apply,
+		client.DryRunAll, client.ForceOwnership, client.FieldOwner(r.FieldManager)); err != nil {
+		return nil, nil
+	}
+	var applied *metav1.FieldsV1
+	for _, entry := range dryRunObject.GetManagedFields() {
+		if entry.Manager == r.FieldManager && entry.Operation == metav1.ManagedFieldsOperationApply && entry.Subresource == "" {
+			applied = entry.FieldsV1
+		}
+	}
+	if applied == nil {
+		return nil, nil
+	}
+	ours, err := ssa.FieldsToSet(*applied)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []string
+	for _, entry := range candidates {
+		theirs, err := ssa.FieldsToSet(*entry.FieldsV1)
+		if err != nil {
+			return nil, err
+		}
+		if !ours.Intersection(&theirs).Empty() {
+			result = append(result, entry.Manager)
+		}
+	}
+	return result, nil
+}
+
+func (r *KustomizationReconciler) apply(ctx context.Context,
+	manager *ssa.ResourceManager,
+	obj *kustomizev1.Kustomization,
+	revision string,
+	objects []*unstructured.Unstructured) (bool, *ssa.ChangeSet, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	if err := normalize.UnstructuredList(objects); err != nil {
+		return false, nil, err
+	}
+
+	if cmeta := obj.Spec.CommonMetadata; cmeta != nil {
+		ssautil.SetCommonMetadata(objects, cmeta.Labels, cmeta.Annotations)
+	}
+
+	ignoreRules, err := newIgnoreRules(obj.Spec.Ignore)
+	if err != nil {
+		return false, nil, err
+	}
+
+	applyOpts := ssa.DefaultApplyOptions()
+	applyOpts.Force = obj.Spec.Force
+	applyOpts.ExclusionSelector = map[string]string{
+		fmt.Sprintf("%s/reconcile", kustomizev1.GroupVersion.Group): kustomizev1.DisabledValue,
+		fmt.Sprintf("%s/ssa", kustomizev1.GroupVersion.Group):       kustomizev1.IgnoreValue,
+	}
+	applyOpts.IfNotPresentSelector = map[string]string{
+		fmt.Sprintf("%s/ssa", kustomizev1.GroupVersion.Group): kustomizev1.IfNotPresentValue,
+	}
+	applyOpts.ForceSelector = map[string]string{
+		fmt.Sprintf("%s/force", kustomizev1.GroupVersion.Group): kustomizev1.EnabledValue,
+	}
+
 	applyOpts.Cleanup = ssa.ApplyCleanupOptions{
 		Annotations: []string{
 			// remove the kubectl annotation
@@ -727,7 +1117,7 @@ func (r *KustomizationReconciler) apply(ctx context.Context,
 			// remove deprecated fluxcd.io labels
 			"fluxcd.io/sync-gc-mark",
 		},
-		FieldManagers: fieldManagers,
+		FieldManagers: r.takeoverFieldManagers(),
 		Exclusions: map[string]string{
 			fmt.Sprintf("%s/ssa", kustomizev1.GroupVersion.Group): kustomizev1.MergeValue,
 		},
@@ -764,6 +1154,10 @@ func (r *KustomizationReconciler) apply(ctx context.Context,
 
 	}
 
+	if err := r.preserveIgnoredFields(ctx, manager, ignoreRules, objects); err != nil {
+		return false, nil, err
+	}
+
 	var changeSetLog strings.Builder
 
 	// validate, apply and wait for CRDs and Namespaces to register
@@ -847,6 +1241,155 @@ func (r *KustomizationReconciler) apply(ctx context.Context,
 	return applyLog != "", resultSet, nil
 }
 
+// preserveIgnoredFields sets the fields matching the ignore rules to their
+// values in the cluster, so that the server-side apply and the dry-run diff
+// don't revert the changes made to them by other controllers. The fields
+// missing from the in-cluster objects are removed, and the objects which
+// don't exist yet keep the values from the manifests.
+func (r *KustomizationReconciler) preserveIgnoredFields(ctx context.Context,
+	manager *ssa.ResourceManager,
+	rules []ignoreRule,
+	objects []*unstructured.Unstructured) error {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	for _, u := range objects {
+		paths := ignoredPaths(rules, u)
+		if len(paths) == 0 {
+			continue
+		}
+
+		existing := &unstructured.Unstructured{}
+		existing.SetGroupVersionKind(u.GroupVersionKind())
+		if err := manager.Client().Get(ctx, client.ObjectKeyFromObject(u), existing); err != nil {
+			if apierrors.IsNotFound(err) || apimeta.IsNoMatchError(err) {
+				continue
+			}
+			return fmt.Errorf("failed to get %s: %w", ssautil.FmtUnstructured(u), err)
+		}
+
+		preserveFields(u, existing, paths)
+	}
+	return nil
+}
+
+// dryRun performs a server-side apply dry-run of the given objects and
+// returns the changes that an apply would result in, including the
+// removal of the stale objects if garbage collection is enabled.
+func (r *KustomizationReconciler) dryRun(ctx context.Context,
+	manager *ssa.ResourceManager,
+	obj *kustomizev1.Kustomization,
+	objects []*unstructured.Unstructured,
+	oldInventory *kustomizev1.ResourceInventory) (*ssa.ChangeSet, error) {
+	if err := normalize.UnstructuredList(objects); err != nil {
+		return nil, err
+	}
+
+	if cmeta := obj.Spec.CommonMetadata; cmeta != nil {
+		ssautil.SetCommonMetadata(objects, cmeta.Labels, cmeta.Annotations)
+	}
+
+	ignoreRules, err := newIgnoreRules(obj.Spec.Ignore)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.preserveIgnoredFields(ctx, manager, ignoreRules, objects); err != nil {
+		return nil, err
+	}
+
+	diffOpts := ssa.DiffOptions{
+		Exclusions: map[string]string{
+			fmt.Sprintf("%s/reconcile", kustomizev1.GroupVersion.Group): kustomizev1.DisabledValue,
+			fmt.Sprintf("%s/ssa", kustomizev1.GroupVersion.Group):       kustomizev1.IgnoreValue,
+		},
+	}
+
+	// The objects in the Namespaces that don't exist yet can't be validated
+	// with a server-side apply dry-run, they are reported as created.
+	newNamespaces := make(map[string]bool)
+
+	// Neither can the custom resources whose CRD is not registered yet.
+	definedKinds := make(map[schema.GroupKind]bool)
+	for _, u := range objects {
+		if gk, ok := definedGroupKind(u); ok {
+			definedKinds[gk] = true
+		}
+	}
+
+	sort.Sort(ssa.SortableUnstructureds(objects))
+	changeSet := ssa.NewChangeSet()
+	for _, u := range objects {
+		if decryptor.IsEncryptedSecret(u) {
+			return nil,
+				fmt.Errorf("%s is SOPS encrypted, configuring decryption is required for this secret to be reconciled",
+					ssautil.FmtUnstructured(u))
+		}
+
+		if newNamespaces[u.GetNamespace()] {
+			changeSet.Add(newChangeSetEntry(u, ssa.CreatedAction))
+			continue
+		}
+
+		entry, _, _, err := manager.Diff(ctx, u, diffOpts)
+		if err != nil {
+			if apimeta.IsNoMatchError(err) && definedKinds[u.GroupVersionKind().GroupKind()] {
+				changeSet.Add(newChangeSetEntry(u, ssa.CreatedAction))
+				continue
+			}
+			return nil, err
+		}
+		changeSet.Add(*entry)
+
+		if entry.Action == ssa.CreatedAction && u.GetAPIVersion() == "v1" && u.GetKind() == "Namespace" {
+			newNamespaces[u.GetName()] = true
+		}
+	}
+
+	if obj.Spec.Prune {
+		newInventory := inventory.New()
+		if err := inventory.AddChangeSet(newInventory, changeSet); err != nil {
+			return nil, err
+		}
+
+		staleObjects, err := inventory.Diff(oldInventory, newInventory)
+		if err != nil {
+			return nil, err
+		}
+
+		// Report only the objects that the garbage collection would delete,
+		// based on their metadata in the cluster.
+		reader := r.uncachedReader(obj, manager)
+		ownerLabels := manager.GetOwnerLabels(obj.Name, obj.Namespace)
+		exclusions := map[string]string{
+			fmt.Sprintf("%s/prune", kustomizev1.GroupVersion.Group):     kustomizev1.DisabledValue,
+			fmt.Sprintf("%s/reconcile", kustomizev1.GroupVersion.Group): kustomizev1.DisabledValue,
+		}
+		for _, u := range staleObjects {
+			existing := &metav1.PartialObjectMetadata{}
+			existing.SetGroupVersionKind(u.GroupVersionKind())
+			if err := reader.Get(ctx, client.ObjectKeyFromObject(u), existing); err != nil {
+				if apierrors.IsNotFound(err) || apimeta.IsNoMatchError(err) {
+					continue
+				}
+				return nil, fmt.Errorf("failed to get %s: %w", ssautil.FmtUnstructured(u), err)
+			}
+
+			live := &unstructured.Unstructured{}
+			live.SetLabels(existing.GetLabels())
+			live.SetAnnotations(existing.GetAnnotations())
+			if !hasLabels(live.GetLabels(), ownerLabels) || ssautil.AnyInMetadata(live, exclusions) {
+				continue
+			}
+
+			changeSet.Add(newChangeSetEntry(u, ssa.DeletedAction))
+		}
+	}
+
+	return changeSet, nil
+}
+
 func (r *KustomizationReconciler) checkHealth(ctx context.Context,
 	manager *ssa.ResourceManager,
 	patcher *patch.SerialPatcher,
@@ -927,6 +1470,7 @@ func (r *KustomizationReconciler) prune(ctx context.Context,
 	revision string,
 	objects []*unstructured.Unstructured) (bool, error) {
 	if !obj.Spec.Prune {
+		r.PhaseMetrics.RecordPruned(obj.GetName(), obj.GetNamespace(), 0)
 		return false, nil
 	}
 
@@ -941,11 +1485,35 @@ func (r *KustomizationReconciler) prune(ctx context.Context,
 		},
 	}
 
+	objects, skipped, err := r.skipNamespacesInUse(ctx, manager, obj, revision, objects)
+	if err != nil {
+		return false, err
+	}
+
+	// Keep the skipped Namespaces in the inventory to retry their
+	// garbage collection at the next reconciliation.
+	for _, ns := range skipped {
+		obj.Status.Inventory.Entries = append(obj.Status.Inventory.Entries, kustomizev1.ResourceRef{
+			ID:      object.UnstructuredToObjMetadata(ns).String(),
+			Version: ns.GroupVersionKind().Version,
+		})
+	}
+
 	changeSet, err := manager.DeleteAll(ctx, objects, opts)
 	if err != nil {
 		return false, err
 	}
 
+	var pruned int
+	if changeSet != nil {
+		for _, entry := range changeSet.Entries {
+			if entry.Action == ssa.DeletedAction {
+				pruned++
+			}
+		}
+	}
+	r.PhaseMetrics.RecordPruned(obj.GetName(), obj.GetNamespace(), pruned)
+
 	// emit event only if the prune operation resulted in changes
 	if changeSet != nil && len(changeSet.Entries) > 0 {
 		log.Info(fmt.Sprintf("garbage collection completed: %s", changeSet.String()))
@@ -956,10 +1524,100 @@ func (r *KustomizationReconciler) prune(ctx context.Context,
 	return false, nil
 }
 
+// skipNamespacesInUse splits the given objects into the objects that can
+// be deleted and the Namespaces that contain objects which are not managed
+// by the Kustomization, and emits an event listing the skipped Namespaces
+// when they differ from the ones skipped previously. Namespaces annotated
+// with 'kustomize.toolkit.fluxcd.io/prune: enabled' are never skipped.
+func (r *KustomizationReconciler) skipNamespacesInUse(ctx context.Context,
+	manager *ssa.ResourceManager,
+	obj *kustomizev1.Kustomization,
+	revision string,
+	objects []*unstructured.Unstructured) ([]*unstructured.Unstructured, []*unstructured.Unstructured, error) {
+	ownerLabels := manager.GetOwnerLabels(obj.Name, obj.Namespace)
+	pruneAnnotation := fmt.Sprintf("%s/prune", kustomizev1.GroupVersion.Group)
+	reader := r.uncachedReader(obj, manager)
+
+	var result, skipped []*unstructured.Unstructured
+	var skippedNames, skippedMsgs []string
+	for _, o := range objects {
+		if o.GetAPIVersion() != "v1" || o.GetKind() != "Namespace" {
+			result = append(result, o)
+			continue
+		}
+
+		ns := &metav1.PartialObjectMetadata{}
+		ns.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Namespace"))
+		if err := reader.Get(ctx, client.ObjectKeyFromObject(o), ns); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			if apierrors.IsForbidden(err) {
+				skipped = append(skipped, o)
+				skippedNames = append(skippedNames, o.GetName())
+				skippedMsgs = append(skippedMsgs, fmt.Sprintf("Namespace/%s (access denied to get the Namespace)", o.GetName()))
+				continue
+			}
+			return nil, nil, fmt.Errorf("failed to get Namespace '%s': %w", o.GetName(), err)
+		}
+		if ns.GetAnnotations()[pruneAnnotation] == kustomizev1.EnabledValue {
+			result = append(result, o)
+			continue
+		}
+
+		reason, err := findUnmanagedObject(ctx, reader, o.GetName(), ownerLabels)
+		if err != nil {
+			return nil, nil, err
+		}
+		if reason != "" {
+			skipped = append(skipped, o)
+			skippedNames = append(skippedNames, o.GetName())
+			skippedMsgs = append(skippedMsgs, fmt.Sprintf("Namespace/%s (%s)", o.GetName(), reason))
+			continue
+		}
+		result = append(result, o)
+	}
+
+	if len(skippedMsgs) == 0 {
+		r.skippedNamespaces.Delete(client.ObjectKeyFromObject(obj))
+		return result, skipped, nil
+	}
+
+	msg := fmt.Sprintf("garbage collection skipped for Namespaces that may contain objects not managed by this Kustomization:\n%s",
+		strings.Join(skippedMsgs, "\n"))
+	ctrl.LoggerFrom(ctx).Info(msg)
+
+	// Keeping the Namespaces is the intended behavior, emit the event
+	// only when the skipped Namespaces change to avoid flooding the
+	// notifications at every reconciliation.
+	sort.Strings(skippedNames)
+	names := strings.Join(skippedNames, ",")
+	if previous, ok := r.skippedNamespaces.Swap(client.ObjectKeyFromObject(obj), names); !ok || previous != names {
+		r.event(obj, revision, eventv1.EventSeverityInfo, msg, nil)
+	}
+
+	return result, skipped, nil
+}
+
+// uncachedReader returns a reader for the objects applied by the given
+// Kustomization which doesn't start informers. The impersonated clients are
+// not cached, while the controller client is, so the API reader is used
+// when no impersonation is configured.
+func (r *KustomizationReconciler) uncachedReader(obj *kustomizev1.Kustomization,
+	manager *ssa.ResourceManager) client.Reader {
+	if r.APIReader != nil &&
+		obj.Spec.KubeConfig == nil &&
+		obj.Spec.ServiceAccountName == "" &&
+		r.DefaultServiceAccount == "" {
+		return r.APIReader
+	}
+	return manager.Client()
+}
+
 func (r *KustomizationReconciler) finalize(ctx context.Context,
 	obj *kustomizev1.Kustomization) (ctrl.Result, error) {
 	log := ctrl.LoggerFrom(ctx)
-	if obj.Spec.Prune &&
+	if obj.GetDeletionPolicy() != kustomizev1.DeletionPolicyOrphan &&
 		!obj.Spec.Suspend &&
 		obj.Status.Inventory != nil &&
 		obj.Status.Inventory.Entries != nil {
@@ -976,13 +1634,13 @@ func (r *KustomizationReconciler) finalize(ctx context.Context,
 			obj.GetNamespace(),
 		)
 		if impersonation.CanImpersonate(ctx) {
-			kubeClient, _, err := impersonation.GetClient(ctx)
+			kubeClient, statusPoller, err := impersonation.GetClient(ctx)
 			if err != nil {
 				return ctrl.Result{}, err
 			}
 
-			resourceManager := ssa.NewResourceManager(kubeClient, nil, ssa.Owner{
-				Field: r.ControllerName,
+			resourceManager := ssa.NewResourceManager(kubeClient, statusPoller, ssa.Owner{
+				Field: r.FieldManager,
 				Group: kustomizev1.GroupVersion.Group,
 			})
 
@@ -995,6 +1653,13 @@ func (r *KustomizationReconciler) finalize(ctx context.Context,
 				},
 			}
 
+			objects, _, err = r.skipNamespacesInUse(ctx, resourceManager, obj, obj.Status.LastAppliedRevision, objects)
+			if err != nil {
+				r.event(obj, obj.Status.LastAppliedRevision, eventv1.EventSeverityError, "pruning for deleted resource failed", nil)
+				// Return the error so we retry the failed garbage collection
+				return ctrl.Result{}, err
+			}
+
 			changeSet, err := resourceManager.DeleteAll(ctx, objects, opts)
 			if err != nil {
 				r.event(obj, obj.Status.LastAppliedRevision, eventv1.EventSeverityError, "pruning for deleted resource failed", nil)
@@ -1004,6 +1669,18 @@ func (r *KustomizationReconciler) finalize(ctx context.Context,
 
 			if changeSet != nil && len(changeSet.Entries) > 0 {
 				r.event(obj, obj.Status.LastAppliedRevision, eventv1.EventSeverityInfo, changeSet.String(), nil)
+
+				if obj.GetDeletionPolicy() == kustomizev1.DeletionPolicyWaitForTermination {
+					if err := resourceManager.WaitForTermination(deletedObjects(objects, changeSet), ssa.WaitOptions{
+						Interval: 2 * time.Second,
+						Timeout:  obj.GetTimeout(),
+					}); err != nil {
+						r.event(obj, obj.Status.LastAppliedRevision, eventv1.EventSeverityError,
+							fmt.Sprintf("waiting for termination of resources failed: %s", err.Error()), nil)
+						// Return the error so we retry the wait for termination
+						return ctrl.Result{}, err
+					}
+				}
 			}
 		} else {
 			// when the account to impersonate is gone, log the stale objects and continue with the finalization
@@ -1013,12 +1690,70 @@ func (r *KustomizationReconciler) finalize(ctx context.Context,
 		}
 	}
 
+	// Delete the phase metrics and the skipped Namespaces of the object.
+	r.PhaseMetrics.Delete(obj.GetName(), obj.GetNamespace())
+	r.skippedNamespaces.Delete(client.ObjectKeyFromObject(obj))
+
 	// Remove our finalizer from the list and update it
 	controllerutil.RemoveFinalizer(obj, kustomizev1.KustomizationFinalizer)
 	// Stop reconciliation as the object is being deleted
 	return ctrl.Result{}, nil
 }
 
+// failureRecord holds the consecutive failed reconciliations of an object
+// and the generation of the object at the last failure.
+type failureRecord struct {
+	count      int
+	generation int64
+}
+
+// getRetryInterval records a failed reconciliation of the given object and
+// returns the duration after which it is retried. When a maximum retry delay
+// is configured, the duration starts at the object's retry interval and
+// doubles with each consecutive failure until the maximum.
+func (r *KustomizationReconciler) getRetryInterval(obj *kustomizev1.Kustomization) time.Duration {
+	failures := r.getFailures(obj) + 1
+	r.failures.Store(client.ObjectKeyFromObject(obj), failureRecord{
+		count:      failures,
+		generation: obj.GetGeneration(),
+	})
+
+	retryInterval := obj.GetRetryInterval()
+	if r.maxRetryDelay <= retryInterval {
+		return retryInterval
+	}
+	for i := 1; i < failures; i++ {
+		retryInterval *= 2
+		if retryInterval >= r.maxRetryDelay {
+			return r.maxRetryDelay
+		}
+	}
+	return retryInterval
+}
+
+// getFailures returns the number of consecutive failed reconciliations
+// of the given object.
+func (r *KustomizationReconciler) getFailures(obj *kustomizev1.Kustomization) int {
+	if record, ok := r.failures.Load(client.ObjectKeyFromObject(obj)); ok {
+		return record.(failureRecord).count
+	}
+	return 0
+}
+
+// resetRetryInterval clears the failures recorded for the given object.
+func (r *KustomizationReconciler) resetRetryInterval(obj *kustomizev1.Kustomization) {
+	r.failures.Delete(client.ObjectKeyFromObject(obj))
+}
+
+// resetRetryIntervalOnChange clears the failures recorded for the given
+// object if its generation changed since the last failure.
+func (r *KustomizationReconciler) resetRetryIntervalOnChange(obj *kustomizev1.Kustomization) {
+	record, ok := r.failures.Load(client.ObjectKeyFromObject(obj))
+	if ok && record.(failureRecord).generation != obj.GetGeneration() {
+		r.resetRetryInterval(obj)
+	}
+}
+
 func (r *KustomizationReconciler) event(obj *kustomizev1.Kustomization,
 	revision, severity, msg string,
 	metadata map[string]string) {
@@ -1057,6 +1792,14 @@ func (r *KustomizationReconciler) finalizeStatus(ctx context.Context,
 		obj.Status.ObservedGeneration = obj.Generation
 	}
 
+	// Remove the Reconciling condition and update the observed generation
+	// if the reconciliation is stalled, as the controller has processed
+	// the current spec and can't make progress without a change.
+	if conditions.IsStalled(obj) {
+		conditions.Delete(obj, meta.ReconcilingCondition)
+		obj.Status.ObservedGeneration = obj.Generation
+	}
+
 	// Set the Reconciling reason to ProgressingWithRetry if the
 	// reconciliation has failed.
 	if conditions.IsFalse(obj, meta.ReadyCondition) &&
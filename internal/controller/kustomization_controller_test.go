@@ -22,16 +22,23 @@ import (
 	"testing"
 	"time"
 
+	"github.com/fluxcd/cli-utils/pkg/object"
 	"github.com/fluxcd/pkg/apis/meta"
+	"github.com/fluxcd/pkg/runtime/conditions"
+	"github.com/fluxcd/pkg/ssa"
 	sourcev1 "github.com/fluxcd/source-controller/api/v1"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 
 	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1"
 )
@@ -136,3 +143,287 @@ func TestKustomizationReconciler_deleteBeforeFinalizer(t *testing.T) {
 	_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(kustomization)})
 	g.Expect(err).NotTo(HaveOccurred())
 }
+
+func TestKustomizationReconciler_cancelledContext(t *testing.T) {
+	g := NewWithT(t)
+
+	testScheme := runtime.NewScheme()
+	g.Expect(kustomizev1.AddToScheme(testScheme)).To(Succeed())
+	g.Expect(sourcev1.AddToScheme(testScheme)).To(Succeed())
+
+	obj := &kustomizev1.Kustomization{}
+	obj.Name = "test-kust"
+	obj.Namespace = "default"
+	obj.Finalizers = []string{kustomizev1.KustomizationFinalizer}
+	obj.Spec = kustomizev1.KustomizationSpec{
+		Interval: metav1.Duration{Duration: 10 * time.Minute},
+		Path:     "./",
+		SourceRef: kustomizev1.CrossNamespaceSourceReference{
+			Name: "missing",
+			Kind: sourcev1.GitRepositoryKind,
+		},
+	}
+
+	// Fail the writes made with a cancelled context, as the API client does.
+	failCancelled := func(ctx context.Context) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return nil
+	}
+	kubeClient := fake.NewClientBuilder().
+		WithScheme(testScheme).
+		WithObjects(obj).
+		WithStatusSubresource(obj).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Patch: func(ctx context.Context, c client.WithWatch, o client.Object, patch client.Patch, opts ...client.PatchOption) error {
+				if err := failCancelled(ctx); err != nil {
+					return err
+				}
+				return c.Patch(ctx, o, patch, opts...)
+			},
+			SubResourcePatch: func(ctx context.Context, c client.Client, subResourceName string, o client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+				if err := failCancelled(ctx); err != nil {
+					return err
+				}
+				return c.SubResource(subResourceName).Patch(ctx, o, patch, opts...)
+			},
+		}).
+		Build()
+
+	r := &KustomizationReconciler{
+		Client:         kubeClient,
+		ControllerName: "kustomize-controller",
+		statusManager:  "gotk-kustomize-controller",
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(obj)})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	result := &kustomizev1.Kustomization{}
+	g.Expect(kubeClient.Get(context.Background(), client.ObjectKeyFromObject(obj), result)).To(Succeed())
+	g.Expect(conditions.IsFalse(result, meta.ReadyCondition)).To(BeTrue())
+	g.Expect(conditions.GetReason(result, meta.ReadyCondition)).To(Equal(kustomizev1.ArtifactFailedReason))
+}
+
+func TestKustomizationReconciler_getRetryInterval(t *testing.T) {
+	g := NewWithT(t)
+
+	obj := &kustomizev1.Kustomization{}
+	obj.Name = "test-kust"
+	obj.Namespace = "default"
+	obj.Spec.Interval = metav1.Duration{Duration: 10 * time.Minute}
+	obj.Spec.RetryInterval = &metav1.Duration{Duration: 4 * time.Second}
+
+	r := &KustomizationReconciler{}
+	g.Expect(r.getRetryInterval(obj)).To(Equal(4 * time.Second))
+	g.Expect(r.getRetryInterval(obj)).To(Equal(4 * time.Second))
+
+	r.resetRetryInterval(obj)
+	r.maxRetryDelay = 10 * time.Second
+	g.Expect(r.getRetryInterval(obj)).To(Equal(4 * time.Second))
+	g.Expect(r.getRetryInterval(obj)).To(Equal(8 * time.Second))
+	g.Expect(r.getRetryInterval(obj)).To(Equal(10 * time.Second))
+	g.Expect(r.getRetryInterval(obj)).To(Equal(10 * time.Second))
+
+	r.resetRetryInterval(obj)
+	g.Expect(r.getRetryInterval(obj)).To(Equal(4 * time.Second))
+	g.Expect(r.getRetryInterval(obj)).To(Equal(8 * time.Second))
+
+	r.resetRetryIntervalOnChange(obj)
+	g.Expect(r.getRetryInterval(obj)).To(Equal(10 * time.Second))
+
+	obj.Generation++
+	r.resetRetryIntervalOnChange(obj)
+	g.Expect(r.getRetryInterval(obj)).To(Equal(4 * time.Second))
+
+	// A maximum below the retry interval disables the backoff.
+	r.maxRetryDelay = time.Second
+	g.Expect(r.getRetryInterval(obj)).To(Equal(4 * time.Second))
+}
+
+func TestKustomizationReconciler_getFailures(t *testing.T) {
+	g := NewWithT(t)
+
+	obj := &kustomizev1.Kustomization{}
+	obj.Name = "test-kust"
+	obj.Namespace = "default"
+	obj.Spec.Interval = metav1.Duration{Duration: 10 * time.Minute}
+
+	r := &KustomizationReconciler{}
+	g.Expect(r.getFailures(obj)).To(Equal(0))
+
+	_ = r.getRetryInterval(obj)
+	_ = r.getRetryInterval(obj)
+	g.Expect(r.getFailures(obj)).To(Equal(2))
+
+	r.resetRetryInterval(obj)
+	g.Expect(r.getFailures(obj)).To(Equal(0))
+
+	// A spec change restarts the count towards the stall limit.
+	_ = r.getRetryInterval(obj)
+	_ = r.getRetryInterval(obj)
+	r.resetRetryIntervalOnChange(obj)
+	g.Expect(r.getFailures(obj)).To(Equal(2))
+
+	obj.Generation++
+	r.resetRetryIntervalOnChange(obj)
+	g.Expect(r.getFailures(obj)).To(Equal(0))
+}
+
+func TestKustomizationReconciler_checkOwnership(t *testing.T) {
+	g := NewWithT(t)
+	id := "owner-" + randStringRunes(5)
+
+	owned := func(name, owner string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: id,
+				Labels: map[string]string{
+					"kustomize.toolkit.fluxcd.io/name":      owner,
+					"kustomize.toolkit.fluxcd.io/namespace": id,
+				},
+			},
+		}
+	}
+
+	toUnstructured := func(name string, annotations map[string]string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion("v1")
+		u.SetKind("ConfigMap")
+		u.SetName(name)
+		u.SetNamespace(id)
+		u.SetAnnotations(annotations)
+		return u
+	}
+
+	// The other Kustomization manages "theirs" and no longer has "released"
+	// in its inventory, while the owner of "orphaned" has been deleted.
+	other := &kustomizev1.Kustomization{}
+	other.Name = "other"
+	other.Namespace = id
+	other.Status.Inventory = &kustomizev1.ResourceInventory{
+		Entries: []kustomizev1.ResourceRef{
+			{ID: object.UnstructuredToObjMetadata(toUnstructured("theirs", nil)).String(), Version: "v1"},
+		},
+	}
+
+	testScheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(testScheme)).To(Succeed())
+	g.Expect(kustomizev1.AddToScheme(testScheme)).To(Succeed())
+
+	kubeClient := fake.NewClientBuilder().
+		WithScheme(testScheme).
+		WithObjects(
+			other,
+			owned("mine", "app"),
+			owned("theirs", "other"),
+			owned("released", "other"),
+			owned("orphaned", "deleted"),
+		).
+		Build()
+
+	obj := &kustomizev1.Kustomization{}
+	obj.Name = "app"
+	obj.Namespace = id
+
+	r := &KustomizationReconciler{Client: kubeClient}
+	manager := ssa.NewResourceManager(kubeClient, nil, ssa.Owner{
+		Field: "kustomize-controller",
+		Group: kustomizev1.GroupVersion.Group,
+	})
+
+	err := r.checkOwnership(context.Background(), manager, obj, []*unstructured.Unstructured{
+		toUnstructured("mine", nil),
+		toUnstructured("new", nil),
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	err = r.checkOwnership(context.Background(), manager, obj, []*unstructured.Unstructured{
+		toUnstructured("theirs", map[string]string{"kustomize.toolkit.fluxcd.io/ssa": "IfNotPresent"}),
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	err = r.checkOwnership(context.Background(), manager, obj, []*unstructured.Unstructured{
+		toUnstructured("released", nil),
+		toUnstructured("orphaned", nil),
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	err = r.checkOwnership(context.Background(), manager, obj, []*unstructured.Unstructured{
+		toUnstructured("mine", nil),
+		toUnstructured("theirs", nil),
+	})
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("ConfigMap/%s/theirs is owned by Kustomization '%s/other'", id, id))
+	g.Expect(err.Error()).NotTo(ContainSubstring("mine"))
+}
+
+func TestKustomizationReconciler_checkOwnership_fieldManagers(t *testing.T) {
+	g := NewWithT(t)
+	id := "owner-" + randStringRunes(5)
+
+	err := createNamespace(id)
+	g.Expect(err).NotTo(HaveOccurred(), "failed to create test namespace")
+
+	newConfigMap := func(name string, data map[string]interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": id,
+			},
+			"data": data,
+		}}
+	}
+
+	// Apply the objects with other field managers.
+	for name, fieldManager := range map[string]string{
+		"shared":   "other-tool",
+		"disjoint": "other-tool",
+		"kubectl":  "kubectl",
+	} {
+		data := map[string]interface{}{"key": "value"}
+		if name == "disjoint" {
+			data = map[string]interface{}{"other": "value"}
+		}
+		g.Expect(k8sClient.Patch(context.Background(), newConfigMap(name, data), client.Apply,
+			client.ForceOwnership, client.FieldOwner(fieldManager))).To(Succeed())
+	}
+
+	obj := &kustomizev1.Kustomization{}
+	obj.Name = "app"
+	obj.Namespace = id
+
+	r := &KustomizationReconciler{
+		Client:         k8sClient,
+		ControllerName: "kustomize-controller",
+		FieldManager:   "kustomize-controller",
+	}
+	manager := ssa.NewResourceManager(k8sClient, nil, ssa.Owner{
+		Field: r.FieldManager,
+		Group: kustomizev1.GroupVersion.Group,
+	})
+
+	err = r.checkOwnership(context.Background(), manager, obj, []*unstructured.Unstructured{
+		newConfigMap("disjoint", map[string]interface{}{"key": "value"}),
+		newConfigMap("kubectl", map[string]interface{}{"key": "value"}),
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	err = r.checkOwnership(context.Background(), manager, obj, []*unstructured.Unstructured{
+		newConfigMap("shared", map[string]interface{}{"key": "new-value"}),
+	})
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("ConfigMap/%s/shared has fields applied by field manager 'other-tool'", id))
+
+	// The dry-run doesn't change the object.
+	result := newConfigMap("shared", nil)
+	g.Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(result), result)).To(Succeed())
+	g.Expect(result.Object["data"]).To(Equal(map[string]interface{}{"key": "value"}))
+}
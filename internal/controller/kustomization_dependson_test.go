@@ -23,13 +23,16 @@ import (
 	"time"
 
 	"github.com/fluxcd/pkg/apis/meta"
+	"github.com/fluxcd/pkg/runtime/conditions"
 	"github.com/fluxcd/pkg/testserver"
 	sourcev1 "github.com/fluxcd/source-controller/api/v1"
 	. "github.com/onsi/gomega"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1"
 )
@@ -183,4 +186,96 @@ spec:
 			return ready.Reason == kustomizev1.DependencyNotReadyReason
 		}, timeout, time.Second).Should(BeTrue())
 	})
+
+	t.Run("stalls due to self dependency", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Eventually(func() error {
+			_ = k8sClient.Get(context.Background(), client.ObjectKeyFromObject(kustomization), resultK)
+			resultK.Spec.DependsOn = []meta.NamespacedObjectReference{
+				{
+					Name: kustomizationKey.Name,
+				},
+			}
+			return k8sClient.Update(context.Background(), resultK)
+		}, timeout, time.Second).Should(BeNil())
+
+		g.Eventually(func() bool {
+			_ = k8sClient.Get(context.Background(), client.ObjectKeyFromObject(kustomization), resultK)
+			return conditions.IsStalled(resultK) &&
+				conditions.GetReason(resultK, meta.ReadyCondition) == kustomizev1.InvalidSpecReason
+		}, timeout, time.Second).Should(BeTrue())
+		g.Expect(resultK.Status.ObservedGeneration).To(Equal(resultK.Generation))
+	})
+}
+
+func TestKustomizationReconciler_checkDependencies_NotApplied(t *testing.T) {
+	newDependency := func(name, reason string) *kustomizev1.Kustomization {
+		k := &kustomizev1.Kustomization{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       name,
+				Namespace:  "apps",
+				Generation: 1,
+			},
+			Spec: kustomizev1.KustomizationSpec{
+				SourceRef: kustomizev1.CrossNamespaceSourceReference{
+					Kind: sourcev1.GitRepositoryKind,
+					Name: "infra",
+				},
+			},
+		}
+		k.Status.ObservedGeneration = 1
+		conditions.MarkTrue(k, meta.ReadyCondition, reason, "")
+		return k
+	}
+
+	testScheme := runtime.NewScheme()
+	g := NewWithT(t)
+	g.Expect(kustomizev1.AddToScheme(testScheme)).To(Succeed())
+
+	r := &KustomizationReconciler{
+		Client: fake.NewClientBuilder().
+			WithScheme(testScheme).
+			WithObjects(
+				newDependency("applied", kustomizev1.ReconciliationSucceededReason),
+				newDependency("dry-run", kustomizev1.DryRunSucceededReason),
+				newDependency("deferred", kustomizev1.OutsideScheduleReason),
+			).
+			Build(),
+	}
+
+	tests := []struct {
+		dependency string
+		wantErr    string
+	}{
+		{dependency: "applied"},
+		{dependency: "dry-run", wantErr: "its changes are not applied (DryRunSucceeded)"},
+		{dependency: "deferred", wantErr: "its changes are not applied (OutsideSchedule)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dependency, func(t *testing.T) {
+			g := NewWithT(t)
+
+			obj := &kustomizev1.Kustomization{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "app",
+					Namespace: "apps",
+				},
+				Spec: kustomizev1.KustomizationSpec{
+					DependsOn: []meta.NamespacedObjectReference{{Name: tt.dependency}},
+					SourceRef: kustomizev1.CrossNamespaceSourceReference{
+						Kind: sourcev1.GitRepositoryKind,
+						Name: "app",
+					},
+				},
+			}
+
+			err := r.checkDependencies(context.Background(), obj, nil)
+			if tt.wantErr == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			g.Expect(err).To(HaveOccurred())
+			g.Expect(err.Error()).To(ContainSubstring(tt.wantErr))
+		})
+	}
 }
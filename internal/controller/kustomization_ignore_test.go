@@ -0,0 +1,281 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fluxcd/pkg/apis/kustomize"
+	"github.com/fluxcd/pkg/ssa"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1"
+)
+
+func TestKustomizationReconciler_ignoredPaths(t *testing.T) {
+	newDeployment := func(name string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion("apps/v1")
+		u.SetKind("Deployment")
+		u.SetName(name)
+		u.SetNamespace("default")
+		return u
+	}
+
+	t.Run("returns the paths of the rules selecting the object", func(t *testing.T) {
+		g := NewWithT(t)
+
+		rules, err := newIgnoreRules([]kustomizev1.IgnoreRule{
+			{
+				Paths: []string{"/spec/replicas"},
+				Target: &kustomize.Selector{
+					Kind: "Deployment",
+					Name: "app",
+				},
+			},
+			{
+				Paths: []string{"/metadata/annotations/example.com~1owner"},
+			},
+		})
+		g.Expect(err).NotTo(HaveOccurred())
+
+		g.Expect(ignoredPaths(rules, newDeployment("app"))).To(Equal([]string{
+			"/spec/replicas",
+			"/metadata/annotations/example.com~1owner",
+		}))
+		g.Expect(ignoredPaths(rules, newDeployment("other"))).To(Equal([]string{
+			"/metadata/annotations/example.com~1owner",
+		}))
+	})
+
+	t.Run("sorts the array indices in reverse order", func(t *testing.T) {
+		g := NewWithT(t)
+
+		rules, err := newIgnoreRules([]kustomizev1.IgnoreRule{
+			{
+				Paths: []string{
+					"/spec/template/spec/containers/1",
+					"/spec/template/spec/containers/10",
+				},
+			},
+			{
+				Paths: []string{
+					"/spec/template/spec/containers/2/image",
+					"/spec/template/spec/containers/2",
+				},
+			},
+		})
+		g.Expect(err).NotTo(HaveOccurred())
+
+		g.Expect(ignoredPaths(rules, newDeployment("app"))).To(Equal([]string{
+			"/spec/template/spec/containers/10",
+			"/spec/template/spec/containers/2/image",
+			"/spec/template/spec/containers/2",
+			"/spec/template/spec/containers/1",
+		}))
+	})
+
+	t.Run("fails with invalid paths", func(t *testing.T) {
+		g := NewWithT(t)
+
+		_, err := newIgnoreRules([]kustomizev1.IgnoreRule{
+			{
+				Paths: []string{"spec/replicas"},
+			},
+		})
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("must start with '/'"))
+	})
+}
+
+func TestKustomizationReconciler_preserveFields(t *testing.T) {
+	newDeployment := func(spec map[string]interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]interface{}{
+				"name":      "app",
+				"namespace": "default",
+			},
+			"spec": spec,
+		}}
+	}
+	newPodSpec := func(containers ...string) map[string]interface{} {
+		var list []interface{}
+		for _, name := range containers {
+			list = append(list, map[string]interface{}{"name": name, "image": name + ":v1"})
+		}
+		return map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{"containers": list},
+			},
+		}
+	}
+
+	t.Run("sets the values of the existing object", func(t *testing.T) {
+		g := NewWithT(t)
+
+		desired := newDeployment(map[string]interface{}{"replicas": int64(1), "paused": false})
+		existing := newDeployment(map[string]interface{}{"replicas": int64(5), "paused": false})
+		preserveFields(desired, existing, []string{"/spec/replicas"})
+
+		g.Expect(desired.Object["spec"]).To(Equal(map[string]interface{}{"replicas": int64(5), "paused": false}))
+	})
+
+	t.Run("sets the values missing from the manifests", func(t *testing.T) {
+		g := NewWithT(t)
+
+		desired := newDeployment(map[string]interface{}{})
+		existing := newDeployment(map[string]interface{}{"replicas": int64(5)})
+		existing.SetAnnotations(map[string]string{"example.com/owner": "team"})
+		preserveFields(desired, existing, []string{"/spec/replicas", "/metadata/annotations/example.com~1owner"})
+
+		g.Expect(desired.Object["spec"]).To(Equal(map[string]interface{}{"replicas": int64(5)}))
+		g.Expect(desired.GetAnnotations()).To(Equal(map[string]string{"example.com/owner": "team"}))
+	})
+
+	t.Run("removes the values missing from the existing object", func(t *testing.T) {
+		g := NewWithT(t)
+
+		desired := newDeployment(map[string]interface{}{"replicas": int64(1), "paused": false})
+		existing := newDeployment(map[string]interface{}{"paused": false})
+		preserveFields(desired, existing, []string{"/spec/replicas"})
+
+		g.Expect(desired.Object["spec"]).To(Equal(map[string]interface{}{"paused": false}))
+	})
+
+	t.Run("keeps the array elements added by other controllers", func(t *testing.T) {
+		g := NewWithT(t)
+
+		desired := newDeployment(newPodSpec("app"))
+		existing := newDeployment(newPodSpec("app", "sidecar", "proxy"))
+		rules, err := newIgnoreRules([]kustomizev1.IgnoreRule{
+			{
+				Paths: []string{
+					"/spec/template/spec/containers/1",
+					"/spec/template/spec/containers/2",
+				},
+			},
+		})
+		g.Expect(err).NotTo(HaveOccurred())
+		preserveFields(desired, existing, ignoredPaths(rules, desired))
+
+		g.Expect(desired.Object["spec"]).To(Equal(newPodSpec("app", "sidecar", "proxy")))
+	})
+
+	t.Run("removes the array elements missing from the existing object", func(t *testing.T) {
+		g := NewWithT(t)
+
+		desired := newDeployment(newPodSpec("app", "sidecar", "proxy"))
+		existing := newDeployment(newPodSpec("app"))
+		rules, err := newIgnoreRules([]kustomizev1.IgnoreRule{
+			{
+				Paths: []string{
+					"/spec/template/spec/containers/1",
+					"/spec/template/spec/containers/2",
+				},
+			},
+		})
+		g.Expect(err).NotTo(HaveOccurred())
+		preserveFields(desired, existing, ignoredPaths(rules, desired))
+
+		g.Expect(desired.Object["spec"]).To(Equal(newPodSpec("app")))
+	})
+}
+
+func TestKustomizationReconciler_preserveIgnoredFields(t *testing.T) {
+	g := NewWithT(t)
+	id := "ignore-" + randStringRunes(5)
+
+	err := createNamespace(id)
+	g.Expect(err).NotTo(HaveOccurred(), "failed to create test namespace")
+
+	newConfigMap := func(name string, data map[string]interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": id,
+			},
+			"data": data,
+		}}
+	}
+
+	r := &KustomizationReconciler{FieldManager: "kustomize-controller"}
+	manager := ssa.NewResourceManager(k8sClient, nil, ssa.Owner{
+		Field: r.FieldManager,
+		Group: kustomizev1.GroupVersion.Group,
+	})
+
+	desired := map[string]interface{}{"key": "value", "replicas": "1"}
+	_, err = manager.ApplyAll(context.Background(), []*unstructured.Unstructured{newConfigMap("app", desired)}, ssa.DefaultApplyOptions())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// Change the ignored field with another field manager.
+	existing := newConfigMap("app", map[string]interface{}{"key": "value", "replicas": "3"})
+	g.Expect(k8sClient.Patch(context.Background(), existing, client.Merge, client.FieldOwner("autoscaler"))).To(Succeed())
+
+	rules, err := newIgnoreRules([]kustomizev1.IgnoreRule{
+		{
+			Paths:  []string{"/data/replicas"},
+			Target: &kustomize.Selector{Kind: "ConfigMap"},
+		},
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	t.Run("keeps the ignored fields when applying other changes", func(t *testing.T) {
+		g := NewWithT(t)
+
+		changed := newConfigMap("app", map[string]interface{}{"key": "new-value", "replicas": "1"})
+		g.Expect(r.preserveIgnoredFields(context.Background(), manager, rules,
+			[]*unstructured.Unstructured{changed})).To(Succeed())
+		g.Expect(changed.Object["data"]).To(Equal(map[string]interface{}{"key": "new-value", "replicas": "3"}))
+
+		changeSet, err := manager.ApplyAll(context.Background(), []*unstructured.Unstructured{changed}, ssa.DefaultApplyOptions())
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(changeSet.Entries[0].Action).To(Equal(ssa.ConfiguredAction))
+
+		result := newConfigMap("app", nil)
+		g.Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(result), result)).To(Succeed())
+		g.Expect(result.Object["data"]).To(Equal(map[string]interface{}{"key": "new-value", "replicas": "3"}))
+	})
+
+	t.Run("reports objects drifting only in ignored paths as unchanged", func(t *testing.T) {
+		g := NewWithT(t)
+
+		unchanged := newConfigMap("app", map[string]interface{}{"key": "new-value", "replicas": "1"})
+		g.Expect(r.preserveIgnoredFields(context.Background(), manager, rules,
+			[]*unstructured.Unstructured{unchanged})).To(Succeed())
+
+		entry, _, _, err := manager.Diff(context.Background(), unchanged, ssa.DiffOptions{})
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(entry.Action).To(Equal(ssa.UnchangedAction))
+	})
+
+	t.Run("keeps the manifest values of new objects", func(t *testing.T) {
+		g := NewWithT(t)
+
+		created := newConfigMap("new", map[string]interface{}{"replicas": "1"})
+		g.Expect(r.preserveIgnoredFields(context.Background(), manager, rules,
+			[]*unstructured.Unstructured{created})).To(Succeed())
+		g.Expect(created.Object["data"]).To(Equal(map[string]interface{}{"replicas": "1"}))
+	})
+}
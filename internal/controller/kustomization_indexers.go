@@ -57,6 +57,10 @@ func (r *KustomizationReconciler) requestsForRevisionChangeOf(indexKey string) h
 		}
 		var dd []dependency.Dependent
 		for i, d := range list.Items {
+			// If the Kustomization is suspended, we should not make a request for it
+			if d.Spec.Suspend {
+				continue
+			}
 			// If the Kustomization is ready and the revision of the artifact equals
 			// to the last attempted revision, we should not make a request for this Kustomization
 			if conditions.IsReady(&list.Items[i]) && repo.GetArtifact().HasRevision(d.Status.LastAttemptedRevision) {
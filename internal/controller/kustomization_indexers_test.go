@@ -0,0 +1,70 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1"
+)
+
+func TestKustomizationReconciler_requestsForRevisionChangeOf(t *testing.T) {
+	g := NewWithT(t)
+
+	testScheme := runtime.NewScheme()
+	g.Expect(kustomizev1.AddToScheme(testScheme)).To(Succeed())
+	g.Expect(sourcev1.AddToScheme(testScheme)).To(Succeed())
+
+	repo := &sourcev1.GitRepository{}
+	repo.Name = "repo"
+	repo.Namespace = "default"
+	repo.Status.Artifact = &sourcev1.Artifact{Revision: "main@sha1:a1b2c3"}
+
+	newKustomization := func(name string, suspend bool) *kustomizev1.Kustomization {
+		obj := &kustomizev1.Kustomization{}
+		obj.Name = name
+		obj.Namespace = "default"
+		obj.Spec.Suspend = suspend
+		obj.Spec.SourceRef = kustomizev1.CrossNamespaceSourceReference{
+			Name: repo.Name,
+			Kind: sourcev1.GitRepositoryKind,
+		}
+		return obj
+	}
+
+	r := &KustomizationReconciler{}
+	indexKey := ".metadata.gitRepository"
+	r.Client = fake.NewClientBuilder().
+		WithScheme(testScheme).
+		WithObjects(
+			newKustomization("active", false),
+			newKustomization("suspended", true),
+		).
+		WithIndex(&kustomizev1.Kustomization{}, indexKey, r.indexBy(sourcev1.GitRepositoryKind)).
+		Build()
+
+	reqs := r.requestsForRevisionChangeOf(indexKey)(context.Background(), repo)
+	g.Expect(reqs).To(HaveLen(1))
+	g.Expect(reqs[0].Name).To(Equal("active"))
+	g.Expect(reqs[0].Namespace).To(Equal("default"))
+}
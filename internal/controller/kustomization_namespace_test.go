@@ -0,0 +1,179 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/fluxcd/pkg/apis/meta"
+	"github.com/fluxcd/pkg/runtime/conditions"
+	"github.com/fluxcd/pkg/testserver"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1"
+)
+
+func TestKustomizationReconciler_CreateNamespace(t *testing.T) {
+	g := NewWithT(t)
+	id := "ns-" + randStringRunes(5)
+	targetNamespace := "target-" + randStringRunes(5)
+	revision := "v1.0.0"
+
+	err := createNamespace(id)
+	g.Expect(err).NotTo(HaveOccurred(), "failed to create test namespace")
+
+	err = createKubeConfigSecret(id)
+	g.Expect(err).NotTo(HaveOccurred(), "failed to create kubeconfig secret")
+
+	manifests := func(name string, data string) []testserver.File {
+		return []testserver.File{
+			{
+				Name: "config.yaml",
+				Body: fmt.Sprintf(`---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %[1]s
+data:
+  key: "%[2]s"
+`, name, data),
+			},
+		}
+	}
+
+	artifact, err := testServer.ArtifactFromFiles(manifests(id, id))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	repositoryName := types.NamespacedName{
+		Name:      fmt.Sprintf("ns-%s", randStringRunes(5)),
+		Namespace: id,
+	}
+
+	err = applyGitRepository(repositoryName, artifact, revision)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	newKustomization := func(namespace string, mode string) *kustomizev1.Kustomization {
+		return &kustomizev1.Kustomization{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("ns-%s", randStringRunes(5)),
+				Namespace: id,
+			},
+			Spec: kustomizev1.KustomizationSpec{
+				Interval: metav1.Duration{Duration: reconciliationInterval},
+				Path:     "./",
+				KubeConfig: &meta.KubeConfigReference{
+					SecretRef: meta.SecretKeyReference{
+						Name: "kubeconfig",
+					},
+				},
+				SourceRef: kustomizev1.CrossNamespaceSourceReference{
+					Name:      repositoryName.Name,
+					Namespace: repositoryName.Namespace,
+					Kind:      sourcev1.GitRepositoryKind,
+				},
+				TargetNamespace: namespace,
+				CreateNamespace: true,
+				Prune:           true,
+				Mode:            mode,
+				CommonMetadata: &kustomizev1.CommonMetadata{
+					Labels: map[string]string{"team": "app"},
+				},
+			},
+		}
+	}
+
+	kustomization := newKustomization(targetNamespace, kustomizev1.ApplyMode)
+	g.Expect(k8sClient.Create(context.Background(), kustomization)).To(Succeed())
+
+	resultK := &kustomizev1.Kustomization{}
+	resultNamespace := &corev1.Namespace{}
+
+	t.Run("creates the target namespace", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Eventually(func() bool {
+			_ = k8sClient.Get(context.Background(), client.ObjectKeyFromObject(kustomization), resultK)
+			return resultK.Status.LastAppliedRevision == revision
+		}, timeout, time.Second).Should(BeTrue())
+
+		g.Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: targetNamespace}, resultNamespace)).To(Succeed())
+		g.Expect(resultNamespace.GetLabels()).To(HaveKeyWithValue("team", "app"))
+
+		resultConfig := &corev1.ConfigMap{}
+		g.Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: id, Namespace: targetNamespace}, resultConfig)).To(Succeed())
+	})
+
+	t.Run("does not update the existing namespace", func(t *testing.T) {
+		g := NewWithT(t)
+		resultNamespace.Labels["team"] = "other"
+		g.Expect(k8sClient.Update(context.Background(), resultNamespace)).To(Succeed())
+
+		newRevision := "v2.0.0"
+		artifact, err := testServer.ArtifactFromFiles(manifests(id, "v2"))
+		g.Expect(err).NotTo(HaveOccurred())
+		err = applyGitRepository(repositoryName, artifact, newRevision)
+		g.Expect(err).NotTo(HaveOccurred())
+
+		g.Eventually(func() bool {
+			_ = k8sClient.Get(context.Background(), client.ObjectKeyFromObject(kustomization), resultK)
+			return resultK.Status.LastAppliedRevision == newRevision
+		}, timeout, time.Second).Should(BeTrue())
+
+		g.Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: targetNamespace}, resultNamespace)).To(Succeed())
+		g.Expect(resultNamespace.GetLabels()).To(HaveKeyWithValue("team", "other"))
+	})
+
+	t.Run("reports the namespace creation in dry-run mode", func(t *testing.T) {
+		g := NewWithT(t)
+		dryRunNamespace := "target-" + randStringRunes(5)
+		dryRunK := newKustomization(dryRunNamespace, kustomizev1.DryRunMode)
+		g.Expect(k8sClient.Create(context.Background(), dryRunK)).To(Succeed())
+
+		g.Eventually(func() bool {
+			_ = k8sClient.Get(context.Background(), client.ObjectKeyFromObject(dryRunK), dryRunK)
+			return conditions.GetReason(dryRunK, meta.ReadyCondition) == kustomizev1.DryRunSucceededReason
+		}, timeout, time.Second).Should(BeTrue())
+
+		err := k8sClient.Get(context.Background(), types.NamespacedName{Name: dryRunNamespace}, &corev1.Namespace{})
+		g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+		events := getEvents(dryRunK.GetName(), map[string]string{"kustomize.toolkit.fluxcd.io/revision": dryRunK.Status.LastAttemptedRevision})
+		g.Expect(events).ToNot(BeEmpty())
+		g.Expect(events[len(events)-1].Message).To(ContainSubstring("Namespace/%s created", dryRunNamespace))
+		g.Expect(events[len(events)-1].Message).To(ContainSubstring("ConfigMap/%s/%s created", dryRunNamespace, id))
+	})
+
+	t.Run("does not prune the namespace", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(k8sClient.Delete(context.Background(), kustomization)).To(Succeed())
+		g.Eventually(func() bool {
+			err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(kustomization), resultK)
+			return apierrors.IsNotFound(err)
+		}, timeout, time.Second).Should(BeTrue())
+
+		g.Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: targetNamespace}, resultNamespace)).To(Succeed())
+		g.Expect(resultNamespace.GetDeletionTimestamp().IsZero()).To(BeTrue())
+	})
+}
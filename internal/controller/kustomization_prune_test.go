@@ -23,14 +23,20 @@ import (
 	"time"
 
 	"github.com/fluxcd/pkg/apis/meta"
+	"github.com/fluxcd/pkg/ssa"
 	"github.com/fluxcd/pkg/testserver"
 	sourcev1 "github.com/fluxcd/source-controller/api/v1"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 
 	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1"
 )
@@ -441,3 +447,264 @@ data:
 	})
 
 }
+
+func TestKustomizationReconciler_PruneNamespaceInUse(t *testing.T) {
+	g := NewWithT(t)
+	id := "gc-" + randStringRunes(5)
+	nsName := "gc-ns-" + randStringRunes(5)
+
+	err := createNamespace(id)
+	g.Expect(err).NotTo(HaveOccurred(), "failed to create test namespace")
+
+	err = createKubeConfigSecret(id)
+	g.Expect(err).NotTo(HaveOccurred(), "failed to create kubeconfig secret")
+
+	manifests := func(withNamespace bool) []testserver.File {
+		files := []testserver.File{
+			{
+				Name: "config.yaml",
+				Body: fmt.Sprintf(`---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %[1]s
+  namespace: %[1]s
+data:
+  key: value
+`, id),
+			},
+		}
+		if withNamespace {
+			files = append(files, testserver.File{
+				Name: "namespace.yaml",
+				Body: fmt.Sprintf(`---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: %[1]s
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: managed
+  namespace: %[1]s
+data:
+  key: value
+`, nsName),
+			})
+		}
+		return files
+	}
+
+	artifact, err := testServer.ArtifactFromFiles(manifests(true))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	repositoryName := types.NamespacedName{
+		Name:      fmt.Sprintf("gc-%s", randStringRunes(5)),
+		Namespace: id,
+	}
+
+	revision := "v1.0.0"
+	err = applyGitRepository(repositoryName, artifact, revision)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	kustomization := &kustomizev1.Kustomization{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("gc-%s", randStringRunes(5)),
+			Namespace: id,
+		},
+		Spec: kustomizev1.KustomizationSpec{
+			Interval: metav1.Duration{Duration: reconciliationInterval},
+			Path:     "./",
+			KubeConfig: &meta.KubeConfigReference{
+				SecretRef: meta.SecretKeyReference{
+					Name: "kubeconfig",
+				},
+			},
+			SourceRef: kustomizev1.CrossNamespaceSourceReference{
+				Name:      repositoryName.Name,
+				Namespace: repositoryName.Namespace,
+				Kind:      sourcev1.GitRepositoryKind,
+			},
+			Prune: true,
+		},
+	}
+
+	g.Expect(k8sClient.Create(context.Background(), kustomization)).To(Succeed())
+
+	resultK := &kustomizev1.Kustomization{}
+	g.Eventually(func() bool {
+		_ = k8sClient.Get(context.Background(), client.ObjectKeyFromObject(kustomization), resultK)
+		return resultK.Status.LastAppliedRevision == revision
+	}, timeout, time.Second).Should(BeTrue())
+
+	unmanaged := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "unmanaged",
+			Namespace: nsName,
+		},
+	}
+	g.Expect(k8sClient.Create(context.Background(), unmanaged)).To(Succeed())
+
+	resultNS := &corev1.Namespace{}
+
+	t.Run("skips namespace with unmanaged objects", func(t *testing.T) {
+		artifact, err := testServer.ArtifactFromFiles(manifests(false))
+		g.Expect(err).NotTo(HaveOccurred())
+		revision = "v2.0.0"
+		err = applyGitRepository(repositoryName, artifact, revision)
+		g.Expect(err).NotTo(HaveOccurred())
+
+		g.Eventually(func() bool {
+			_ = k8sClient.Get(context.Background(), client.ObjectKeyFromObject(kustomization), resultK)
+			return resultK.Status.LastAppliedRevision == revision
+		}, timeout, time.Second).Should(BeTrue())
+
+		g.Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: nsName}, resultNS)).To(Succeed())
+		g.Expect(resultNS.GetDeletionTimestamp().IsZero()).To(BeTrue())
+
+		managed := &corev1.ConfigMap{}
+		err = k8sClient.Get(context.Background(), types.NamespacedName{Name: "managed", Namespace: nsName}, managed)
+		g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+		g.Expect(resultK.Status.Inventory.Entries).To(ContainElement(kustomizev1.ResourceRef{
+			ID:      fmt.Sprintf("_%s__Namespace", nsName),
+			Version: "v1",
+		}))
+
+		events := getEvents(resultK.GetName(), map[string]string{"kustomize.toolkit.fluxcd.io/revision": revision})
+		g.Expect(events).To(ContainElement(WithTransform(func(e corev1.Event) string { return e.Message },
+			ContainSubstring("ConfigMap/%s/unmanaged", nsName))))
+	})
+
+	t.Run("prunes namespace when annotated", func(t *testing.T) {
+		g.Eventually(func() error {
+			_ = k8sClient.Get(context.Background(), types.NamespacedName{Name: nsName}, resultNS)
+			resultNS.SetAnnotations(map[string]string{
+				fmt.Sprintf("%s/prune", kustomizev1.GroupVersion.Group): kustomizev1.EnabledValue,
+			})
+			return k8sClient.Update(context.Background(), resultNS)
+		}, timeout, time.Second).Should(Succeed())
+
+		reconcileRequestAt := metav1.Now().String()
+		g.Eventually(func() error {
+			_ = k8sClient.Get(context.Background(), client.ObjectKeyFromObject(kustomization), resultK)
+			resultK.SetAnnotations(map[string]string{
+				meta.ReconcileRequestAnnotation: reconcileRequestAt,
+			})
+			return k8sClient.Update(context.Background(), resultK)
+		}, timeout, time.Second).Should(Succeed())
+
+		g.Eventually(func() bool {
+			_ = k8sClient.Get(context.Background(), types.NamespacedName{Name: nsName}, resultNS)
+			return !resultNS.GetDeletionTimestamp().IsZero()
+		}, timeout, time.Second).Should(BeTrue())
+	})
+}
+
+func Test_findUnmanagedObject(t *testing.T) {
+	ownerLabels := map[string]string{
+		"kustomize.toolkit.fluxcd.io/name":      "app",
+		"kustomize.toolkit.fluxcd.io/namespace": "flux-system",
+	}
+	objects := []client.Object{
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "kube-root-ca.crt", Namespace: "app"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "managed", Namespace: "app", Labels: ownerLabels}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "unmanaged", Namespace: "other"}},
+	}
+
+	newClient := func(funcs interceptor.Funcs) client.Client {
+		return fake.NewClientBuilder().
+			WithScheme(clientgoscheme.Scheme).
+			WithObjects(objects...).
+			WithInterceptorFuncs(funcs).
+			Build()
+	}
+
+	t.Run("namespace not in use", func(t *testing.T) {
+		g := NewWithT(t)
+		reason, err := findUnmanagedObject(context.Background(), newClient(interceptor.Funcs{}), "app", ownerLabels)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(reason).To(BeEmpty())
+	})
+
+	t.Run("namespace with unmanaged object", func(t *testing.T) {
+		g := NewWithT(t)
+		reason, err := findUnmanagedObject(context.Background(), newClient(interceptor.Funcs{}), "other", ownerLabels)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(reason).To(Equal("contains Secret/other/unmanaged"))
+	})
+
+	t.Run("namespace with forbidden list", func(t *testing.T) {
+		g := NewWithT(t)
+		c := newClient(interceptor.Funcs{
+			List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+				if list.GetObjectKind().GroupVersionKind().Kind == "PodList" {
+					return apierrors.NewForbidden(corev1.Resource("pods"), "", fmt.Errorf("denied"))
+				}
+				return c.List(ctx, list, opts...)
+			},
+		})
+		reason, err := findUnmanagedObject(context.Background(), c, "app", ownerLabels)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(reason).To(Equal("access denied to list Pod objects"))
+	})
+}
+
+func TestKustomizationReconciler_skipNamespacesInUse(t *testing.T) {
+	g := NewWithT(t)
+
+	c := fake.NewClientBuilder().
+		WithScheme(clientgoscheme.Scheme).
+		WithObjects(
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "app"}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "other"}},
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "unmanaged", Namespace: "other"}},
+		).
+		Build()
+
+	recorder := record.NewFakeRecorder(10)
+	r := &KustomizationReconciler{EventRecorder: recorder}
+	manager := ssa.NewResourceManager(c, nil, ssa.Owner{
+		Field: "kustomize-controller",
+		Group: kustomizev1.GroupVersion.Group,
+	})
+
+	obj := &kustomizev1.Kustomization{}
+	obj.Name = "app"
+	obj.Namespace = "flux-system"
+
+	newNamespace := func(name string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion("v1")
+		u.SetKind("Namespace")
+		u.SetName(name)
+		return u
+	}
+	skip := func(names ...string) []*unstructured.Unstructured {
+		var objects []*unstructured.Unstructured
+		for _, name := range names {
+			objects = append(objects, newNamespace(name))
+		}
+		result, skipped, err := r.skipNamespacesInUse(context.Background(), manager, obj, "v1", objects)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(len(result) + len(skipped)).To(Equal(len(objects)))
+		return skipped
+	}
+
+	g.Expect(skip("app", "other")).To(ConsistOf(newNamespace("other")))
+	g.Expect(recorder.Events).To(HaveLen(1))
+	g.Expect(<-recorder.Events).To(And(
+		HavePrefix("Normal"),
+		ContainSubstring("Namespace/other (contains Secret/other/unmanaged)"),
+	))
+
+	// The event is not emitted again while the same Namespaces are kept.
+	g.Expect(skip("app", "other")).To(HaveLen(1))
+	g.Expect(recorder.Events).To(BeEmpty())
+
+	// It is emitted again once the Namespaces kept have changed.
+	g.Expect(skip("app")).To(BeEmpty())
+	g.Expect(skip("other")).To(HaveLen(1))
+	g.Expect(recorder.Events).To(HaveLen(1))
+}
@@ -17,11 +17,29 @@ limitations under the License.
 package controller
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 
+	"github.com/fluxcd/cli-utils/pkg/object"
+	"github.com/fluxcd/pkg/apis/kustomize"
 	"github.com/fluxcd/pkg/ssa"
+	"github.com/fluxcd/pkg/ssa/jsondiff"
+	ssautil "github.com/fluxcd/pkg/ssa/utils"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1"
 )
 
 // MkdirTempAbs creates a tmp dir and returns the absolute path to the dir.
@@ -51,3 +69,375 @@ func HasChanged(action ssa.Action) bool {
 		return true
 	}
 }
+
+// definedGroupKind returns the group and kind of the custom resources
+// defined by the given object, if it is a CustomResourceDefinition.
+func definedGroupKind(u *unstructured.Unstructured) (schema.GroupKind, bool) {
+	if !ssautil.IsCRD(u) {
+		return schema.GroupKind{}, false
+	}
+	group, _, _ := unstructured.NestedString(u.Object, "spec", "group")
+	kind, _, _ := unstructured.NestedString(u.Object, "spec", "names", "kind")
+	return schema.GroupKind{Group: group, Kind: kind}, kind != ""
+}
+
+// newChangeSetEntry returns a change set entry with the given action
+// for the given object.
+func newChangeSetEntry(u *unstructured.Unstructured, action ssa.Action) ssa.ChangeSetEntry {
+	return ssa.ChangeSetEntry{
+		ObjMetadata:  object.UnstructuredToObjMetadata(u),
+		GroupVersion: u.GroupVersionKind().GroupVersion().String(),
+		Subject:      ssautil.FmtUnstructured(u),
+		Action:       action,
+	}
+}
+
+// deletedObjects returns the objects for which the given change set
+// contains a deletion entry.
+func deletedObjects(objects []*unstructured.Unstructured, changeSet *ssa.ChangeSet) []*unstructured.Unstructured {
+	deleted := make(map[object.ObjMetadata]struct{})
+	for _, entry := range changeSet.Entries {
+		if entry.Action == ssa.DeletedAction {
+			deleted[entry.ObjMetadata] = struct{}{}
+		}
+	}
+
+	var result []*unstructured.Unstructured
+	for _, u := range objects {
+		if _, ok := deleted[object.UnstructuredToObjMetadata(u)]; ok {
+			result = append(result, u)
+		}
+	}
+	return result
+}
+
+// inventoryContains returns true if the given inventory has an entry for the
+// given object.
+func inventoryContains(inv *kustomizev1.ResourceInventory, u *unstructured.Unstructured) bool {
+	if inv == nil {
+		return false
+	}
+	id := object.UnstructuredToObjMetadata(u).String()
+	for _, entry := range inv.Entries {
+		if entry.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// namespaceInUseKinds are the kinds of objects looked up in a Namespace
+// before pruning it, to detect workloads and data not managed by the
+// Kustomization.
+var namespaceInUseKinds = []schema.GroupVersionKind{
+	{Version: "v1", Kind: "ConfigMap"},
+	{Version: "v1", Kind: "PersistentVolumeClaim"},
+	{Version: "v1", Kind: "Pod"},
+	{Version: "v1", Kind: "Secret"},
+	{Version: "v1", Kind: "Service"},
+	{Group: "apps", Version: "v1", Kind: "DaemonSet"},
+	{Group: "apps", Version: "v1", Kind: "Deployment"},
+	{Group: "apps", Version: "v1", Kind: "StatefulSet"},
+	{Group: "batch", Version: "v1", Kind: "CronJob"},
+	{Group: "batch", Version: "v1", Kind: "Job"},
+}
+
+// findUnmanagedObject looks up the namespaceInUseKinds objects in the given
+// namespace, and returns the reason why the namespace is in use: the first
+// object which is not managed by the owner with the given labels, or the
+// kind of objects which can't be listed. Objects that have owner references,
+// and the objects created by Kubernetes in each namespace, are considered
+// managed. An empty reason is returned if the namespace is not in use.
+func findUnmanagedObject(ctx context.Context, c client.Reader, namespace string, ownerLabels map[string]string) (string, error) {
+	for _, gvk := range namespaceInUseKinds {
+		list := &metav1.PartialObjectMetadataList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := c.List(ctx, list, client.InNamespace(namespace)); err != nil {
+			if apimeta.IsNoMatchError(err) {
+				continue
+			}
+			if apierrors.IsForbidden(err) {
+				return fmt.Sprintf("access denied to list %s objects", gvk.Kind), nil
+			}
+			return "", fmt.Errorf("failed to list %s objects in Namespace '%s': %w", gvk.Kind, namespace, err)
+		}
+
+		for _, item := range list.Items {
+			if !item.GetDeletionTimestamp().IsZero() ||
+				len(item.GetOwnerReferences()) > 0 ||
+				isNamespaceDefault(gvk.Kind, item) ||
+				hasLabels(item.GetLabels(), ownerLabels) {
+				continue
+			}
+			return fmt.Sprintf("contains %s/%s/%s", gvk.Kind, namespace, item.GetName()), nil
+		}
+	}
+	return "", nil
+}
+
+// isNamespaceDefault returns true if the given object is created by
+// Kubernetes in each namespace.
+func isNamespaceDefault(kind string, obj metav1.PartialObjectMetadata) bool {
+	switch kind {
+	case "ConfigMap":
+		return obj.GetName() == "kube-root-ca.crt"
+	case "Secret":
+		_, ok := obj.GetAnnotations()[corev1.ServiceAccountNameKey]
+		return ok
+	}
+	return false
+}
+
+// hasLabels returns true if all the given labels are set on the object.
+func hasLabels(objLabels, labels map[string]string) bool {
+	for k, v := range labels {
+		if objLabels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// withTargetNamespace appends the given Namespace to the objects, unless
+// it's already part of them. The Namespace is annotated to be created only
+// if not present and to be skipped by garbage collection.
+func withTargetNamespace(objects []*unstructured.Unstructured, namespace string) []*unstructured.Unstructured {
+	for _, o := range objects {
+		if o.GetAPIVersion() == "v1" && o.GetKind() == "Namespace" && o.GetName() == namespace {
+			return objects
+		}
+	}
+
+	ns := &unstructured.Unstructured{}
+	ns.SetAPIVersion("v1")
+	ns.SetKind("Namespace")
+	ns.SetName(namespace)
+	ns.SetAnnotations(map[string]string{
+		fmt.Sprintf("%s/ssa", kustomizev1.GroupVersion.Group):   kustomizev1.IfNotPresentValue,
+		fmt.Sprintf("%s/prune", kustomizev1.GroupVersion.Group): kustomizev1.DisabledValue,
+	})
+	return append(objects, ns)
+}
+
+// ignoreRule is an ignore rule of a Kustomization with a parsed target.
+type ignoreRule struct {
+	selector *jsondiff.SelectorRegex
+	paths    []string
+}
+
+// newIgnoreRules parses the targets and the JSON pointers of the given
+// ignore rules.
+func newIgnoreRules(rules []kustomizev1.IgnoreRule) ([]ignoreRule, error) {
+	result := make([]ignoreRule, 0, len(rules))
+	for _, rule := range rules {
+		if err := validateIgnoreRule(rule); err != nil {
+			return nil, err
+		}
+		sr, err := newIgnoreSelector(rule.Target)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, ignoreRule{selector: sr, paths: rule.Paths})
+	}
+	return result, nil
+}
+
+// ignoredPaths returns the paths of the rules selecting the given object.
+// The paths are sorted in reverse order, with array indices compared as
+// numbers.
+func ignoredPaths(rules []ignoreRule, u *unstructured.Unstructured) []string {
+	var paths []string
+	for _, rule := range rules {
+		if rule.selector.MatchUnstructured(u) {
+			paths = append(paths, rule.paths...)
+		}
+	}
+	sort.SliceStable(paths, func(i, j int) bool {
+		return compareJSONPointers(paths[i], paths[j]) > 0
+	})
+	return paths
+}
+
+// compareJSONPointers compares the reference tokens of the given JSON
+// pointers, numerically if both tokens are array indices.
+func compareJSONPointers(a, b string) int {
+	x, _ := parseJSONPointer(a)
+	y, _ := parseJSONPointer(b)
+	for i := 0; i < len(x) && i < len(y); i++ {
+		if x[i] == y[i] {
+			continue
+		}
+		xi, xErr := strconv.Atoi(x[i])
+		yi, yErr := strconv.Atoi(y[i])
+		if xErr == nil && yErr == nil {
+			return xi - yi
+		}
+		return strings.Compare(x[i], y[i])
+	}
+	return len(x) - len(y)
+}
+
+// validateIgnoreRule checks that the target selector and the JSON
+// pointers of the given rule can be parsed.
+func validateIgnoreRule(rule kustomizev1.IgnoreRule) error {
+	if _, err := newIgnoreSelector(rule.Target); err != nil {
+		return err
+	}
+	for _, path := range rule.Paths {
+		if _, err := parseJSONPointer(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newIgnoreSelector(target *kustomize.Selector) (*jsondiff.SelectorRegex, error) {
+	if target == nil {
+		return nil, nil
+	}
+	return jsondiff.NewSelectorRegex(&jsondiff.Selector{
+		Group:              target.Group,
+		Version:            target.Version,
+		Kind:               target.Kind,
+		Name:               target.Name,
+		Namespace:          target.Namespace,
+		AnnotationSelector: target.AnnotationSelector,
+		LabelSelector:      target.LabelSelector,
+	})
+}
+
+// parseJSONPointer splits the given RFC 6901 JSON pointer into its
+// unescaped reference tokens.
+func parseJSONPointer(pointer string) ([]string, error) {
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer '%s', must start with '/'", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(t)
+	}
+	return tokens, nil
+}
+
+// preserveFields copies the values referenced by the given paths from the
+// existing object to the desired object. The paths missing from the existing
+// object are removed from the desired object, starting with the last array
+// indices so that the removals don't shift the indices left to remove, then
+// the other values are set, starting with the first array indices.
+func preserveFields(desired, existing *unstructured.Unstructured, paths []string) {
+	var found []string
+	for _, path := range paths {
+		tokens, err := parseJSONPointer(path)
+		if err != nil {
+			continue
+		}
+		if _, ok := getField(existing.Object, tokens); !ok {
+			removeField(desired.Object, tokens)
+			continue
+		}
+		found = append(found, path)
+	}
+
+	for i := len(found) - 1; i >= 0; i-- {
+		tokens, _ := parseJSONPointer(found[i])
+		value, _ := getField(existing.Object, tokens)
+		setField(desired.Object, tokens, runtime.DeepCopyJSONValue(value))
+	}
+}
+
+// getField returns the value referenced by the tokens in the given node.
+func getField(node interface{}, tokens []string) (interface{}, bool) {
+	for _, token := range tokens {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			v, ok := n[token]
+			if !ok {
+				return nil, false
+			}
+			node = v
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(n) {
+				return nil, false
+			}
+			node = n[i]
+		default:
+			return nil, false
+		}
+	}
+	return node, true
+}
+
+// setField sets the value referenced by the tokens in the given node, and
+// returns the node. Missing maps are created, and an array element is only
+// appended when the index is the length of the array. A nil node is
+// returned if a missing parent can't be created.
+func setField(node interface{}, tokens []string, value interface{}) interface{} {
+	if len(tokens) == 0 {
+		return value
+	}
+	switch n := node.(type) {
+	case nil:
+		if _, err := strconv.Atoi(tokens[0]); err == nil {
+			if tokens[0] == "0" && len(tokens) == 1 {
+				return []interface{}{value}
+			}
+			return nil
+		}
+		child := setField(nil, tokens[1:], value)
+		if child == nil {
+			return nil
+		}
+		return map[string]interface{}{tokens[0]: child}
+	case map[string]interface{}:
+		if child := setField(n[tokens[0]], tokens[1:], value); child != nil {
+			n[tokens[0]] = child
+		}
+		return n
+	case []interface{}:
+		i, err := strconv.Atoi(tokens[0])
+		if err != nil || i < 0 || i > len(n) {
+			return n
+		}
+		if i == len(n) {
+			if len(tokens) > 1 {
+				return n
+			}
+			return append(n, value)
+		}
+		n[i] = setField(n[i], tokens[1:], value)
+		return n
+	default:
+		return node
+	}
+}
+
+// removeField removes the value referenced by the tokens from the given
+// node, and returns the node. Missing values are ignored.
+func removeField(node interface{}, tokens []string) interface{} {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		v, ok := n[tokens[0]]
+		if !ok {
+			return n
+		}
+		if len(tokens) == 1 {
+			delete(n, tokens[0])
+			return n
+		}
+		n[tokens[0]] = removeField(v, tokens[1:])
+		return n
+	case []interface{}:
+		i, err := strconv.Atoi(tokens[0])
+		if err != nil || i < 0 || i >= len(n) {
+			return n
+		}
+		if len(tokens) == 1 {
+			return append(n[:i], n[i+1:]...)
+		}
+		n[i] = removeField(n[i], tokens[1:])
+		return n
+	default:
+		return node
+	}
+}
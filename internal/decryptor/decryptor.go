@@ -127,6 +127,9 @@ type Decryptor struct {
 	// injected into most resources, causing the integrity check to fail.
 	// Mostly kept around for feature completeness and documentation purposes.
 	checkSopsMac bool
+	// kmsOnly restricts the decryption to master keys held by a remote key
+	// management service, i.e. the PGP and age keys are not allowed.
+	kmsOnly bool
 
 	// gnuPGHome is the absolute path of the GnuPG home directory used to
 	// decrypt PGP data. When empty, the systems' GnuPG keyring is used.
@@ -153,27 +156,43 @@ type Decryptor struct {
 	localServiceOnce sync.Once
 }
 
+// Option configures a Decryptor.
+type Option func(d *Decryptor)
+
+// WithKMSOnly restricts the decryption to the AWS KMS, Azure Key Vault,
+// GCP KMS and Hashicorp Vault master keys. Importing PGP or age keys, and
+// decrypting files which can only be decrypted with such keys, fails.
+func WithKMSOnly(enabled bool) Option {
+	return func(d *Decryptor) {
+		d.kmsOnly = enabled
+	}
+}
+
 // NewDecryptor creates a new Decryptor for the given kustomization.
 // gnuPGHome can be empty, in which case the systems' keyring is used.
-func NewDecryptor(root string, client client.Client, kustomization *kustomizev1.Kustomization, maxFileSize int64, gnuPGHome string) *Decryptor {
-	return &Decryptor{
+func NewDecryptor(root string, client client.Client, kustomization *kustomizev1.Kustomization, maxFileSize int64, gnuPGHome string, opts ...Option) *Decryptor {
+	d := &Decryptor{
 		root:          root,
 		client:        client,
 		kustomization: kustomization,
 		maxFileSize:   maxFileSize,
 		gnuPGHome:     pgp.GnuPGHome(gnuPGHome),
 	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
 }
 
 // NewTempDecryptor creates a new Decryptor, with a temporary GnuPG
 // home directory to Decryptor.ImportKeys() into.
-func NewTempDecryptor(root string, client client.Client, kustomization *kustomizev1.Kustomization) (*Decryptor, func(), error) {
+func NewTempDecryptor(root string, client client.Client, kustomization *kustomizev1.Kustomization, opts ...Option) (*Decryptor, func(), error) {
 	gnuPGHome, err := pgp.NewGnuPGHome()
 	if err != nil {
 		return nil, nil, fmt.Errorf("cannot create decryptor: %w", err)
 	}
 	cleanup := func() { _ = os.RemoveAll(gnuPGHome.String()) }
-	return NewDecryptor(root, client, kustomization, maxEncryptedFileSize, gnuPGHome.String()), cleanup, nil
+	return NewDecryptor(root, client, kustomization, maxEncryptedFileSize, gnuPGHome.String(), opts...), cleanup, nil
 }
 
 // IsEncryptedSecret checks if the given object is a Kubernetes Secret encrypted
@@ -218,7 +237,12 @@ func (d *Decryptor) ImportKeys(ctx context.Context) error {
 
 		var err error
 		for name, value := range secret.Data {
-			switch filepath.Ext(name) {
+			ext := filepath.Ext(name)
+			if d.kmsOnly && (ext == DecryptionPGPExt || ext == DecryptionAgeExt) {
+				return fmt.Errorf("failed to import '%s' data from %s decryption Secret '%s': PGP and age keys are not allowed in KMS-only decryption mode",
+					name, provider, secretName)
+			}
+			switch ext {
 			case DecryptionPGPExt:
 				if err = d.gnuPGHome.Import(value); err != nil {
 					return fmt.Errorf("failed to import '%s' data from %s decryption Secret '%s': %w", name, provider, secretName, err)
@@ -286,6 +310,12 @@ func (d *Decryptor) SopsDecryptWithFormat(data []byte, inputFormat, outputFormat
 		return nil, sopsUserErr(fmt.Sprintf("failed to load encrypted %s data", sopsFormatToString[inputFormat]), err)
 	}
 
+	if d.kmsOnly {
+		if err := removeOfflineKeys(&tree.Metadata); err != nil {
+			return nil, err
+		}
+	}
+
 	for _, group := range tree.Metadata.KeyGroups {
 		// Sort MasterKeys in the group so offline ones are tried first
 		sort.SliceStable(group, func(i, j int) bool {
@@ -556,6 +586,27 @@ func (d *Decryptor) sopsEncryptWithFormat(metadata sops.Metadata, data []byte, i
 	return out, nil
 }
 
+// removeOfflineKeys removes the PGP and age master keys from the key groups
+// of the given metadata. It returns an error if none of the remaining master
+// keys can be used to retrieve the data key.
+func removeOfflineKeys(metadata *sops.Metadata) error {
+	var found bool
+	for i, group := range metadata.KeyGroups {
+		var keys sops.KeyGroup
+		for _, key := range group {
+			if !intkeyservice.IsOfflineMethod(key) {
+				keys = append(keys, key)
+			}
+		}
+		metadata.KeyGroups[i] = keys
+		found = found || len(keys) > 0
+	}
+	if !found {
+		return fmt.Errorf("cannot get sops data key: file is only encrypted with PGP or age keys, which are not allowed in KMS-only decryption mode")
+	}
+	return nil
+}
+
 // keyServiceServer returns the SOPS (local) key service clients used to serve
 // decryption requests. loadKeyServiceServer() is only configured on the first
 // call.
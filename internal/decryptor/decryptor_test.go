@@ -85,6 +85,7 @@ func TestDecryptor_ImportKeys(t *testing.T) {
 		name        string
 		decryption  *kustomizev1.Decryption
 		secret      *corev1.Secret
+		kmsOnly     bool
 		wantErr     bool
 		inspectFunc func(g *GomegaWithT, decryptor *Decryptor)
 	}{
@@ -168,6 +169,29 @@ func TestDecryptor_ImportKeys(t *testing.T) {
 				g.Expect(decryptor.ageIdentities).To(HaveLen(0))
 			},
 		},
+		{
+			name: "age key in KMS-only mode",
+			decryption: &kustomizev1.Decryption{
+				Provider: provider,
+				SecretRef: &meta.LocalObjectReference{
+					Name: "age-secret",
+				},
+			},
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "age-secret",
+					Namespace: provider,
+				},
+				Data: map[string][]byte{
+					"age" + DecryptionAgeExt: ageKey,
+				},
+			},
+			kmsOnly: true,
+			wantErr: true,
+			inspectFunc: func(g *GomegaWithT, decryptor *Decryptor) {
+				g.Expect(decryptor.ageIdentities).To(HaveLen(0))
+			},
+		},
 		{
 			name: "HC Vault token",
 			decryption: &kustomizev1.Decryption{
@@ -375,7 +399,7 @@ clientSecret: some-client-secret`),
 				},
 			}
 
-			d, cleanup, err := NewTempDecryptor("", cb.Build(), &kustomization)
+			d, cleanup, err := NewTempDecryptor("", cb.Build(), &kustomization, WithKMSOnly(tt.kmsOnly))
 			g.Expect(err).ToNot(HaveOccurred())
 			t.Cleanup(cleanup)
 
@@ -446,6 +470,30 @@ func TestDecryptor_SopsDecryptWithFormat(t *testing.T) {
 		g.Expect(out).To(Equal([]byte("key: value\n")))
 	})
 
+	t.Run("KMS-only mode with age key", func(t *testing.T) {
+		g := NewWithT(t)
+
+		ageID, err := extage.GenerateX25519Identity()
+		g.Expect(err).ToNot(HaveOccurred())
+
+		kd := &Decryptor{
+			ageIdentities: age.ParsedIdentities{ageID},
+		}
+
+		format := formats.Json
+		encData, err := kd.sopsEncryptWithFormat(sops.Metadata{
+			KeyGroups: []sops.KeyGroup{
+				{&age.MasterKey{Recipient: ageID.Recipient().String()}},
+			},
+		}, []byte("{\"key\": \"value\"}\n"), format, format)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		kd.kmsOnly = true
+		_, err = kd.SopsDecryptWithFormat(encData, format, format)
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("not allowed in KMS-only decryption mode"))
+	})
+
 	t.Run("invalid JSON data", func(t *testing.T) {
 		g := NewWithT(t)
 
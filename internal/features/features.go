@@ -44,6 +44,15 @@ const (
 	// should fail if a variable without a default value is declared in files
 	// but is missing from the input vars.
 	StrictPostBuildSubstitutions = "StrictPostBuildSubstitutions"
+
+	// DetectOwnershipConflicts controls whether the controller should refuse
+	// to apply objects which are owned by another Kustomization, or whose
+	// fields are applied by another field manager.
+	//
+	// When enabled, the metadata of each object is fetched from the cluster
+	// before applying, and the objects with fields applied by other field
+	// managers are applied with a dry-run, resulting in additional API requests.
+	DetectOwnershipConflicts = "DetectOwnershipConflicts"
 )
 
 var features = map[string]bool{
@@ -59,6 +68,9 @@ var features = map[string]bool{
 	// StrictPostBuildSubstitutions
 	// opt-in from v1.3
 	StrictPostBuildSubstitutions: false,
+	// DetectOwnershipConflicts
+	// opt-in from v1.3
+	DetectOwnershipConflicts: false,
 }
 
 // FeatureGates contains a list of all supported feature gates and
@@ -0,0 +1,145 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"time"
+
+	"github.com/fluxcd/pkg/ssa"
+	"github.com/prometheus/client_golang/prometheus"
+	crtlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// The phases of a Kustomization reconciliation recorded by the
+// phase duration histogram.
+const (
+	FetchPhase  = "fetch"
+	BuildPhase  = "build"
+	ApplyPhase  = "apply"
+	PrunePhase  = "prune"
+	HealthPhase = "health"
+)
+
+// The actions recorded by the objects gauge.
+const (
+	CreatedAction    = "created"
+	ConfiguredAction = "configured"
+	UnchangedAction  = "unchanged"
+	PrunedAction     = "pruned"
+)
+
+// Recorder records the metrics of the apply phases of the Kustomizations.
+// A nil Recorder is valid and records nothing.
+type Recorder struct {
+	objectsGauge      *prometheus.GaugeVec
+	durationHistogram *prometheus.HistogramVec
+}
+
+// MustMakeRecorder creates a Recorder and registers its collectors in the
+// controller-runtime metrics registry, it panics if the registration fails.
+func MustMakeRecorder() *Recorder {
+	recorder := NewRecorder()
+	crtlmetrics.Registry.MustRegister(recorder.Collectors()...)
+	return recorder
+}
+
+// NewRecorder returns a new Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{
+		objectsGauge: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "gotk_kustomization_objects",
+				Help: "The number of objects per action of the last apply and garbage collection of a Kustomization.",
+			},
+			[]string{"name", "namespace", "action"},
+		),
+		durationHistogram: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name: "gotk_kustomization_phase_duration_seconds",
+				Help: "The duration in seconds of a phase of a Kustomization reconciliation.",
+				// Use a histogram with 10 count buckets between 10ms - 30min
+				Buckets: prometheus.ExponentialBucketsRange(10e-3, 1800, 10),
+			},
+			[]string{"name", "namespace", "phase"},
+		),
+	}
+}
+
+// Collectors returns the Prometheus collectors of the Recorder.
+func (r *Recorder) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		r.objectsGauge,
+		r.durationHistogram,
+	}
+}
+
+// RecordApplied records the number of created, configured and unchanged
+// objects of the given apply change set.
+func (r *Recorder) RecordApplied(name, namespace string, changeSet *ssa.ChangeSet) {
+	if r == nil {
+		return
+	}
+	counts := map[string]int{
+		CreatedAction:    0,
+		ConfiguredAction: 0,
+		UnchangedAction:  0,
+	}
+	if changeSet != nil {
+		for _, entry := range changeSet.Entries {
+			switch entry.Action {
+			case ssa.CreatedAction:
+				counts[CreatedAction]++
+			case ssa.ConfiguredAction:
+				counts[ConfiguredAction]++
+			case ssa.UnchangedAction:
+				counts[UnchangedAction]++
+			}
+		}
+	}
+	for action, count := range counts {
+		r.objectsGauge.WithLabelValues(name, namespace, action).Set(float64(count))
+	}
+}
+
+// RecordPruned records the number of objects deleted by the garbage collection.
+func (r *Recorder) RecordPruned(name, namespace string, count int) {
+	if r == nil {
+		return
+	}
+	r.objectsGauge.WithLabelValues(name, namespace, PrunedAction).Set(float64(count))
+}
+
+// RecordDuration records the duration since start of the given phase.
+func (r *Recorder) RecordDuration(name, namespace, phase string, start time.Time) {
+	if r == nil {
+		return
+	}
+	r.durationHistogram.WithLabelValues(name, namespace, phase).Observe(time.Since(start).Seconds())
+}
+
+// Delete deletes the metrics of the given Kustomization.
+func (r *Recorder) Delete(name, namespace string) {
+	if r == nil {
+		return
+	}
+	for _, action := range []string{CreatedAction, ConfiguredAction, UnchangedAction, PrunedAction} {
+		r.objectsGauge.DeleteLabelValues(name, namespace, action)
+	}
+	for _, phase := range []string{FetchPhase, BuildPhase, ApplyPhase, PrunePhase, HealthPhase} {
+		r.durationHistogram.DeleteLabelValues(name, namespace, phase)
+	}
+}
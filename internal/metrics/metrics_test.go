@@ -0,0 +1,71 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fluxcd/pkg/ssa"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRecorder_RecordApplied(t *testing.T) {
+	g := NewWithT(t)
+
+	r := NewRecorder()
+	changeSet := ssa.NewChangeSet()
+	changeSet.Add(ssa.ChangeSetEntry{Subject: "Deployment/default/app", Action: ssa.CreatedAction})
+	changeSet.Add(ssa.ChangeSetEntry{Subject: "Service/default/app", Action: ssa.ConfiguredAction})
+	changeSet.Add(ssa.ChangeSetEntry{Subject: "ConfigMap/default/app", Action: ssa.ConfiguredAction})
+
+	r.RecordApplied("app", "default", changeSet)
+	r.RecordPruned("app", "default", 4)
+
+	g.Expect(testutil.ToFloat64(r.objectsGauge.WithLabelValues("app", "default", CreatedAction))).To(Equal(float64(1)))
+	g.Expect(testutil.ToFloat64(r.objectsGauge.WithLabelValues("app", "default", ConfiguredAction))).To(Equal(float64(2)))
+	g.Expect(testutil.ToFloat64(r.objectsGauge.WithLabelValues("app", "default", UnchangedAction))).To(Equal(float64(0)))
+	g.Expect(testutil.ToFloat64(r.objectsGauge.WithLabelValues("app", "default", PrunedAction))).To(Equal(float64(4)))
+
+	r.RecordApplied("app", "default", ssa.NewChangeSet())
+	g.Expect(testutil.ToFloat64(r.objectsGauge.WithLabelValues("app", "default", ConfiguredAction))).To(Equal(float64(0)))
+}
+
+func TestRecorder_Delete(t *testing.T) {
+	g := NewWithT(t)
+
+	r := NewRecorder()
+	r.RecordApplied("app", "default", ssa.NewChangeSet())
+	r.RecordPruned("app", "default", 1)
+	r.RecordDuration("app", "default", FetchPhase, time.Now())
+	r.RecordDuration("other", "default", FetchPhase, time.Now())
+	g.Expect(testutil.CollectAndCount(r.objectsGauge)).To(Equal(4))
+	g.Expect(testutil.CollectAndCount(r.durationHistogram)).To(Equal(2))
+
+	r.Delete("app", "default")
+	g.Expect(testutil.CollectAndCount(r.objectsGauge)).To(Equal(0))
+	g.Expect(testutil.CollectAndCount(r.durationHistogram)).To(Equal(1))
+}
+
+func TestRecorder_Nil(t *testing.T) {
+	var r *Recorder
+	r.RecordApplied("app", "default", nil)
+	r.RecordPruned("app", "default", 1)
+	r.RecordDuration("app", "default", ApplyPhase, time.Now())
+	r.Delete("app", "default")
+}
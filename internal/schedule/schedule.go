@@ -0,0 +1,395 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata"
+
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1"
+)
+
+// Expression is a parsed five fields cron expression.
+type Expression struct {
+	minute, hour, dom, month, dow uint64
+
+	// domStar and dowStar record if the day fields start with '*', as in
+	// '*' or '*/n', which changes how they are combined when matching a time.
+	domStar, dowStar bool
+}
+
+type bounds struct {
+	name     string
+	min, max int
+}
+
+var (
+	minuteBounds = bounds{"minute", 0, 59}
+	hourBounds   = bounds{"hour", 0, 23}
+	domBounds    = bounds{"day of month", 1, 31}
+	monthBounds  = bounds{"month", 1, 12}
+	dowBounds    = bounds{"day of week", 0, 7}
+)
+
+// Parse parses a standard five fields cron expression
+// (minute, hour, day of month, month, day of week).
+// Each field accepts '*', single values, ranges 'a-b',
+// lists 'a,b' and steps '*/n', 'a-b/n' or 'a/n'.
+func Parse(expr string) (*Expression, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron expression '%s': expected 5 fields, found %d", expr, len(fields))
+	}
+
+	e := &Expression{
+		domStar: strings.HasPrefix(fields[2], "*"),
+		dowStar: strings.HasPrefix(fields[4], "*"),
+	}
+	var err error
+	for i, f := range []struct {
+		field *uint64
+		b     bounds
+	}{
+		{&e.minute, minuteBounds},
+		{&e.hour, hourBounds},
+		{&e.dom, domBounds},
+		{&e.month, monthBounds},
+		{&e.dow, dowBounds},
+	} {
+		if *f.field, err = parseField(fields[i], f.b); err != nil {
+			return nil, fmt.Errorf("invalid cron expression '%s': %w", expr, err)
+		}
+	}
+
+	// Sunday can be specified as both 0 and 7.
+	if e.dow&(1<<7) != 0 {
+		e.dow = e.dow&^(1<<7) | 1
+	}
+
+	return e, nil
+}
+
+func parseField(field string, b bounds) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			s, err := strconv.Atoi(part[i+1:])
+			if err != nil || s <= 0 {
+				return 0, fmt.Errorf("invalid step '%s' in %s field", part[i+1:], b.name)
+			}
+			rangePart, step = part[:i], s
+		}
+
+		start, end := b.min, b.max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			lo, hi, _ := strings.Cut(rangePart, "-")
+			var err error
+			if start, err = parseValue(lo, b); err != nil {
+				return 0, err
+			}
+			if end, err = parseValue(hi, b); err != nil {
+				return 0, err
+			}
+			if start > end {
+				return 0, fmt.Errorf("invalid range '%s' in %s field", rangePart, b.name)
+			}
+		default:
+			v, err := parseValue(rangePart, b)
+			if err != nil {
+				return 0, err
+			}
+			start = v
+			// A single value without a step matches only that value.
+			if step == 1 {
+				end = v
+			}
+		}
+
+		for v := start; v <= end; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+	return set, nil
+}
+
+func parseValue(s string, b bounds) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value '%s' in %s field", s, b.name)
+	}
+	if v < b.min || v > b.max {
+		return 0, fmt.Errorf("value %d out of range [%d-%d] in %s field", v, b.min, b.max, b.name)
+	}
+	return v, nil
+}
+
+// Matches returns true if the given time, truncated to the minute,
+// is matched by the expression.
+func (e *Expression) Matches(t time.Time) bool {
+	return e.minute&(1<<uint(t.Minute())) != 0 &&
+		e.hour&(1<<uint(t.Hour())) != 0 &&
+		e.month&(1<<uint(t.Month())) != 0 &&
+		e.matchesDay(t)
+}
+
+// matchesDay returns true if the day of the given time is matched by the
+// day of month and day of week fields. As in standard cron, when both
+// fields are restricted a day matches if either of them matches, otherwise
+// both of them must match.
+func (e *Expression) matchesDay(t time.Time) bool {
+	domMatch := e.dom&(1<<uint(t.Day())) != 0
+	dowMatch := e.dow&(1<<uint(t.Weekday())) != 0
+	if !e.domStar && !e.dowStar {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
+
+// next returns the first minute after t matched by the expression, if it
+// is not after until. The months, days and hours which don't match are
+// skipped as a whole, in the location of t.
+func (e *Expression) next(t, until time.Time) (time.Time, bool) {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	for !t.After(until) {
+		switch {
+		case e.month&(1<<uint(t.Month())) == 0:
+			t = later(t, time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc))
+		case !e.matchesDay(t):
+			t = later(t, time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc))
+		case e.hour&(1<<uint(t.Hour())) == 0:
+			t = t.Add(time.Duration(60-t.Minute()) * time.Minute)
+		case e.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// prev returns the last minute at or before t matched by the expression,
+// if it is after since. The months, days and hours which don't match are
+// skipped as a whole, in the location of t.
+func (e *Expression) prev(t, since time.Time) (time.Time, bool) {
+	loc := t.Location()
+	t = t.Truncate(time.Minute)
+	for t.After(since) {
+		switch {
+		case e.month&(1<<uint(t.Month())) == 0:
+			t = earlier(t, time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc).Add(-time.Minute))
+		case !e.matchesDay(t):
+			t = earlier(t, time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc).Add(-time.Minute))
+		case e.hour&(1<<uint(t.Hour())) == 0:
+			t = t.Add(-time.Duration(t.Minute()+1) * time.Minute)
+		case e.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(-time.Minute)
+		default:
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// later returns next if it is after t, or the minute after t otherwise.
+// This guards against the normalization of the midnights skipped by a
+// daylight saving time change.
+func later(t, next time.Time) time.Time {
+	if next.After(t) {
+		return next
+	}
+	return t.Add(time.Minute)
+}
+
+// earlier returns prev if it is before t, or the minute before t otherwise.
+func earlier(t, prev time.Time) time.Time {
+	if prev.Before(t) {
+		return prev
+	}
+	return t.Add(-time.Minute)
+}
+
+// maxWindowDuration limits the lookback performed when evaluating a window.
+const maxWindowDuration = 31 * 24 * time.Hour
+
+// Window is a parsed schedule window.
+type Window struct {
+	expr     *Expression
+	duration time.Duration
+}
+
+// NewWindow parses the given schedule window.
+func NewWindow(w kustomizev1.ScheduleWindow) (*Window, error) {
+	expr, err := Parse(w.Cron)
+	if err != nil {
+		return nil, err
+	}
+	if w.Duration.Duration <= 0 || w.Duration.Duration > maxWindowDuration {
+		return nil, fmt.Errorf("invalid window duration '%s' for cron '%s': must be greater than zero and at most %s",
+			w.Duration.Duration, w.Cron, maxWindowDuration)
+	}
+	return &Window{expr: expr, duration: w.Duration.Duration}, nil
+}
+
+// Contains returns true if the given time falls within the window,
+// i.e. if the window was started by the cron expression less than
+// duration ago.
+func (w *Window) Contains(now time.Time) bool {
+	_, ok := w.start(now)
+	return ok
+}
+
+// start returns the start of the window containing the given time.
+func (w *Window) start(now time.Time) (time.Time, bool) {
+	return w.expr.prev(now, now.Add(-w.duration))
+}
+
+// Validate checks that the cron expressions, durations and the time zone
+// of the given schedule are valid.
+func Validate(s *kustomizev1.Schedule) error {
+	_, err := newSchedule(s)
+	return err
+}
+
+// IsOpen returns true if the given time falls within one of the allow
+// windows, or if there are no allow windows, and outside all the deny
+// windows of the schedule. A nil schedule is always open.
+func IsOpen(s *kustomizev1.Schedule, now time.Time) (bool, error) {
+	sc, err := newSchedule(s)
+	if err != nil {
+		return false, err
+	}
+	if sc == nil {
+		return true, nil
+	}
+	return sc.isOpen(now.In(sc.location)), nil
+}
+
+// NextOpen returns the first minute after the given time at which the
+// schedule is open, looking ahead at most the given duration. It returns
+// false if the schedule doesn't open within that duration.
+func NextOpen(s *kustomizev1.Schedule, now time.Time, limit time.Duration) (time.Time, bool, error) {
+	sc, err := newSchedule(s)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	if sc == nil {
+		return now, true, nil
+	}
+
+	now = now.In(sc.location)
+	until := now.Add(limit)
+	for t := now.Truncate(time.Minute).Add(time.Minute); !t.After(until); {
+		if sc.isOpen(t) {
+			return t, true, nil
+		}
+		next, ok := sc.nextChange(t, until)
+		if !ok {
+			break
+		}
+		t = next
+	}
+	return time.Time{}, false, nil
+}
+
+type schedule struct {
+	allow, deny []*Window
+	location    *time.Location
+}
+
+func newSchedule(s *kustomizev1.Schedule) (*schedule, error) {
+	if s == nil {
+		return nil, nil
+	}
+
+	sc := &schedule{location: time.UTC}
+	if s.TimeZone != "" {
+		loc, err := time.LoadLocation(s.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time zone '%s': %w", s.TimeZone, err)
+		}
+		sc.location = loc
+	}
+	for _, w := range s.Allow {
+		window, err := NewWindow(w)
+		if err != nil {
+			return nil, err
+		}
+		sc.allow = append(sc.allow, window)
+	}
+	for _, w := range s.Deny {
+		window, err := NewWindow(w)
+		if err != nil {
+			return nil, err
+		}
+		sc.deny = append(sc.deny, window)
+	}
+	return sc, nil
+}
+
+// isOpen returns true if the given time, in the schedule location, falls
+// within the allow windows and outside the deny windows.
+func (sc *schedule) isOpen(now time.Time) bool {
+	for _, w := range sc.deny {
+		if w.Contains(now) {
+			return false
+		}
+	}
+	if len(sc.allow) == 0 {
+		return true
+	}
+	for _, w := range sc.allow {
+		if w.Contains(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// nextChange returns the first time after the given time, at which the
+// schedule is closed, that the schedule may open. The schedule can't open
+// before the end of the deny windows containing the given time, otherwise
+// it can only open when an allow window starts.
+func (sc *schedule) nextChange(t, until time.Time) (time.Time, bool) {
+	var end time.Time
+	for _, w := range sc.deny {
+		if start, ok := w.start(t); ok && start.Add(w.duration).After(end) {
+			end = start.Add(w.duration)
+		}
+	}
+	if !end.IsZero() {
+		// Round up to the minute, as windows start on whole minutes.
+		if rounded := end.Truncate(time.Minute); rounded.Before(end) {
+			end = rounded.Add(time.Minute)
+		}
+		return end, true
+	}
+
+	var next time.Time
+	for _, w := range sc.allow {
+		if start, ok := w.expr.next(t, until); ok && (next.IsZero() || start.Before(next)) {
+			next = start
+		}
+	}
+	return next, !next.IsZero()
+}
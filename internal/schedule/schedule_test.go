@@ -0,0 +1,421 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		wantErr bool
+	}{
+		{name: "all wildcards", expr: "* * * * *"},
+		{name: "values", expr: "30 2 1 6 0"},
+		{name: "ranges and steps", expr: "*/15 9-17 1-31/2 1,6,12 1-5"},
+		{name: "sunday as 7", expr: "0 0 * * 7"},
+		{name: "start with step", expr: "5/10 * * * *"},
+		{name: "too few fields", expr: "* * * *", wantErr: true},
+		{name: "too many fields", expr: "* * * * * *", wantErr: true},
+		{name: "minute out of range", expr: "60 * * * *", wantErr: true},
+		{name: "day of month zero", expr: "* * 0 * *", wantErr: true},
+		{name: "reversed range", expr: "* 17-9 * * *", wantErr: true},
+		{name: "invalid step", expr: "*/0 * * * *", wantErr: true},
+		{name: "names not supported", expr: "* * * * MON", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			_, err := Parse(tt.expr)
+			g.Expect(err != nil).To(Equal(tt.wantErr))
+		})
+	}
+}
+
+func TestExpression_Matches(t *testing.T) {
+	// 2024-03-01 is a Friday.
+	friday := time.Date(2024, 3, 1, 18, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		expr string
+		time time.Time
+		want bool
+	}{
+		{name: "wildcard", expr: "* * * * *", time: friday, want: true},
+		{name: "exact", expr: "30 18 1 3 5", time: friday, want: true},
+		{name: "minute mismatch", expr: "0 18 * * *", time: friday, want: false},
+		{name: "hour range", expr: "* 9-17 * * *", time: friday, want: false},
+		{name: "step", expr: "*/15 * * * *", time: friday, want: true},
+		{name: "day of week", expr: "* * * * 1-4", time: friday, want: false},
+		{name: "sunday as 7", expr: "* * * * 7", time: friday.AddDate(0, 0, 2), want: true},
+		{name: "day of month or week", expr: "* * 15 * 5", time: friday, want: true},
+		{name: "day of month and wildcard week", expr: "* * 15 * *", time: friday, want: false},
+		{name: "stepped wildcard day of month and day of week", expr: "* * */2 * 5", time: friday.AddDate(0, 0, 7), want: false},
+		{name: "day of month and stepped wildcard day of week", expr: "* * 15 * */2", time: friday.AddDate(0, 0, 14), want: false},
+		{name: "stepped range day of month or day of week", expr: "* * 1-31/2 * 5", time: friday.AddDate(0, 0, 7), want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			e, err := Parse(tt.expr)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(e.Matches(tt.time)).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestExpression_nextAndPrev(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Scan each minute around the daylight saving time changes and the
+	// end of February to check the results against a brute-force search.
+	for _, expr := range []string{
+		"30 2 * * *",
+		"*/20 1-3 * * *",
+		"0 0 29,31 * *",
+		"15 12 */2 * 0",
+		"0 9 1 * 1-5",
+	} {
+		for _, from := range []time.Time{
+			time.Date(2024, 3, 9, 22, 0, 0, 0, newYork),
+			time.Date(2024, 11, 2, 22, 0, 0, 0, newYork),
+			time.Date(2024, 2, 27, 0, 0, 0, 0, time.UTC),
+		} {
+			t.Run(expr+" from "+from.String(), func(t *testing.T) {
+				g := NewWithT(t)
+				e, err := Parse(expr)
+				g.Expect(err).ToNot(HaveOccurred())
+
+				until := from.Add(96 * time.Hour)
+				var matches []time.Time
+				for m := from; !m.After(until); m = m.Add(time.Minute) {
+					if e.Matches(m) {
+						matches = append(matches, m)
+					}
+				}
+
+				var got []time.Time
+				for m, ok := e.next(from.Add(-time.Minute), until); ok; m, ok = e.next(m, until) {
+					got = append(got, m)
+				}
+				g.Expect(got).To(Equal(matches))
+
+				got = nil
+				for m, ok := e.prev(until, from.Add(-time.Minute)); ok; m, ok = e.prev(m.Add(-time.Minute), from.Add(-time.Minute)) {
+					got = append([]time.Time{m}, got...)
+				}
+				g.Expect(got).To(Equal(matches))
+			})
+		}
+	}
+}
+
+func TestIsOpen(t *testing.T) {
+	// Business hours on weekdays.
+	workHours := kustomizev1.ScheduleWindow{
+		Cron:     "0 9 * * 1-5",
+		Duration: metav1.Duration{Duration: 8 * time.Hour},
+	}
+	// Friday evening until Monday morning.
+	weekendFreeze := kustomizev1.ScheduleWindow{
+		Cron:     "0 16 * * 5",
+		Duration: metav1.Duration{Duration: 65 * time.Hour},
+	}
+
+	tests := []struct {
+		name     string
+		schedule *kustomizev1.Schedule
+		time     time.Time
+		want     bool
+		wantErr  bool
+	}{
+		{
+			name: "nil schedule",
+			time: time.Date(2024, 3, 2, 3, 0, 0, 0, time.UTC),
+			want: true,
+		},
+		{
+			name:     "inside allow window",
+			schedule: &kustomizev1.Schedule{Allow: []kustomizev1.ScheduleWindow{workHours}},
+			time:     time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC),
+			want:     true,
+		},
+		{
+			name:     "at the end of allow window",
+			schedule: &kustomizev1.Schedule{Allow: []kustomizev1.ScheduleWindow{workHours}},
+			time:     time.Date(2024, 3, 4, 17, 0, 0, 0, time.UTC),
+			want:     false,
+		},
+		{
+			name:     "outside allow window",
+			schedule: &kustomizev1.Schedule{Allow: []kustomizev1.ScheduleWindow{workHours}},
+			time:     time.Date(2024, 3, 2, 12, 0, 0, 0, time.UTC),
+			want:     false,
+		},
+		{
+			name:     "inside deny window",
+			schedule: &kustomizev1.Schedule{Deny: []kustomizev1.ScheduleWindow{weekendFreeze}},
+			time:     time.Date(2024, 3, 4, 8, 59, 0, 0, time.UTC),
+			want:     false,
+		},
+		{
+			name:     "outside deny window",
+			schedule: &kustomizev1.Schedule{Deny: []kustomizev1.ScheduleWindow{weekendFreeze}},
+			time:     time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC),
+			want:     true,
+		},
+		{
+			name: "deny takes precedence",
+			schedule: &kustomizev1.Schedule{
+				Allow: []kustomizev1.ScheduleWindow{workHours},
+				Deny:  []kustomizev1.ScheduleWindow{weekendFreeze},
+			},
+			time: time.Date(2024, 3, 1, 16, 30, 0, 0, time.UTC),
+			want: false,
+		},
+		{
+			name: "time zone",
+			schedule: &kustomizev1.Schedule{
+				Allow:    []kustomizev1.ScheduleWindow{workHours},
+				TimeZone: "America/New_York",
+			},
+			time: time.Date(2024, 3, 4, 20, 0, 0, 0, time.UTC),
+			want: true,
+		},
+		{
+			name: "allow window lasting over the daylight saving time change",
+			schedule: &kustomizev1.Schedule{
+				Allow: []kustomizev1.ScheduleWindow{{
+					Cron:     "0 0 * * *",
+					Duration: metav1.Duration{Duration: 3 * time.Hour},
+				}},
+				TimeZone: "America/New_York",
+			},
+			// 01:30 EST, the second 01:30 of the day, 2h30m after midnight.
+			time: time.Date(2024, 11, 3, 6, 30, 0, 0, time.UTC),
+			want: true,
+		},
+		{
+			name: "allow window ended by the daylight saving time change",
+			schedule: &kustomizev1.Schedule{
+				Allow: []kustomizev1.ScheduleWindow{{
+					Cron:     "0 0 * * *",
+					Duration: metav1.Duration{Duration: 3 * time.Hour},
+				}},
+				TimeZone: "America/New_York",
+			},
+			// 02:30 EST, 3h30m after midnight.
+			time: time.Date(2024, 11, 3, 7, 30, 0, 0, time.UTC),
+			want: false,
+		},
+		{
+			name: "deny window lasting over the end of the month",
+			schedule: &kustomizev1.Schedule{Deny: []kustomizev1.ScheduleWindow{{
+				Cron:     "0 22 31 * *",
+				Duration: metav1.Duration{Duration: 4 * time.Hour},
+			}}},
+			time: time.Date(2024, 2, 1, 1, 0, 0, 0, time.UTC),
+			want: false,
+		},
+		{
+			name: "invalid time zone",
+			schedule: &kustomizev1.Schedule{
+				Allow:    []kustomizev1.ScheduleWindow{workHours},
+				TimeZone: "Mars/Olympus_Mons",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid duration",
+			schedule: &kustomizev1.Schedule{Allow: []kustomizev1.ScheduleWindow{
+				{Cron: "* * * * *"},
+			}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			got, err := IsOpen(tt.schedule, tt.time)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(Validate(tt.schedule)).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestNextOpen(t *testing.T) {
+	workHours := kustomizev1.ScheduleWindow{
+		Cron:     "0 9 * * 1-5",
+		Duration: metav1.Duration{Duration: 8 * time.Hour},
+	}
+	lunchFreeze := kustomizev1.ScheduleWindow{
+		Cron:     "0 12 * * *",
+		Duration: metav1.Duration{Duration: time.Hour},
+	}
+
+	tests := []struct {
+		name     string
+		schedule *kustomizev1.Schedule
+		time     time.Time
+		limit    time.Duration
+		want     time.Time
+		wantOk   bool
+	}{
+		{
+			name:   "nil schedule",
+			time:   time.Date(2024, 3, 2, 3, 0, 0, 0, time.UTC),
+			limit:  time.Hour,
+			want:   time.Date(2024, 3, 2, 3, 0, 0, 0, time.UTC),
+			wantOk: true,
+		},
+		{
+			name:     "allow window opening within the limit",
+			schedule: &kustomizev1.Schedule{Allow: []kustomizev1.ScheduleWindow{workHours}},
+			time:     time.Date(2024, 3, 4, 8, 20, 30, 0, time.UTC),
+			limit:    time.Hour,
+			want:     time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC),
+			wantOk:   true,
+		},
+		{
+			name:     "allow window opening after the limit",
+			schedule: &kustomizev1.Schedule{Allow: []kustomizev1.ScheduleWindow{workHours}},
+			time:     time.Date(2024, 3, 2, 12, 0, 0, 0, time.UTC),
+			limit:    time.Hour,
+			wantOk:   false,
+		},
+		{
+			name:     "deny window closing within the limit",
+			schedule: &kustomizev1.Schedule{Deny: []kustomizev1.ScheduleWindow{lunchFreeze}},
+			time:     time.Date(2024, 3, 2, 12, 15, 0, 0, time.UTC),
+			limit:    time.Hour,
+			want:     time.Date(2024, 3, 2, 13, 0, 0, 0, time.UTC),
+			wantOk:   true,
+		},
+		{
+			name: "deny window overlapping the allow window",
+			schedule: &kustomizev1.Schedule{
+				Allow: []kustomizev1.ScheduleWindow{workHours},
+				Deny:  []kustomizev1.ScheduleWindow{lunchFreeze},
+			},
+			time:   time.Date(2024, 3, 4, 12, 15, 0, 0, time.UTC),
+			limit:  2 * time.Hour,
+			want:   time.Date(2024, 3, 4, 13, 0, 0, 0, time.UTC),
+			wantOk: true,
+		},
+		{
+			name: "allow window skipped by the daylight saving time change",
+			schedule: &kustomizev1.Schedule{
+				Allow: []kustomizev1.ScheduleWindow{{
+					Cron:     "30 2 * * *",
+					Duration: metav1.Duration{Duration: time.Hour},
+				}},
+				TimeZone: "America/New_York",
+			},
+			// 02:30 doesn't exist on 2024-03-10, the next window opens at
+			// 02:30 EDT on the next day.
+			time:   time.Date(2024, 3, 10, 5, 0, 0, 0, time.UTC),
+			limit:  48 * time.Hour,
+			want:   time.Date(2024, 3, 11, 6, 30, 0, 0, time.UTC),
+			wantOk: true,
+		},
+		{
+			name: "deny window repeated by the daylight saving time change",
+			schedule: &kustomizev1.Schedule{
+				Deny: []kustomizev1.ScheduleWindow{{
+					Cron:     "30 1 * * *",
+					Duration: metav1.Duration{Duration: 30 * time.Minute},
+				}},
+				TimeZone: "America/New_York",
+			},
+			// 01:45 EDT, the window closes at 01:00 EST and opens again
+			// at 01:30 EST.
+			time:   time.Date(2024, 11, 3, 5, 45, 0, 0, time.UTC),
+			limit:  time.Hour,
+			want:   time.Date(2024, 11, 3, 6, 0, 0, 0, time.UTC),
+			wantOk: true,
+		},
+		{
+			name: "allow window skipping the months without the day",
+			schedule: &kustomizev1.Schedule{Allow: []kustomizev1.ScheduleWindow{{
+				Cron:     "0 0 31 * *",
+				Duration: metav1.Duration{Duration: time.Hour},
+			}}},
+			time:   time.Date(2024, 4, 1, 0, 30, 0, 0, time.UTC),
+			limit:  62 * 24 * time.Hour,
+			want:   time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC),
+			wantOk: true,
+		},
+		{
+			name: "allow window at the start of the next year",
+			schedule: &kustomizev1.Schedule{Allow: []kustomizev1.ScheduleWindow{{
+				Cron:     "0 0 1 * *",
+				Duration: metav1.Duration{Duration: time.Hour},
+			}}},
+			time:   time.Date(2024, 12, 31, 12, 0, 0, 0, time.UTC),
+			limit:  24 * time.Hour,
+			want:   time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			wantOk: true,
+		},
+		{
+			name: "deny windows overlapping for a month",
+			schedule: &kustomizev1.Schedule{Deny: []kustomizev1.ScheduleWindow{{
+				Cron:     "* * * * *",
+				Duration: metav1.Duration{Duration: 31 * 24 * time.Hour},
+			}}},
+			time:   time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC),
+			limit:  365 * 24 * time.Hour,
+			wantOk: false,
+		},
+		{
+			name: "allow window never opening",
+			schedule: &kustomizev1.Schedule{Allow: []kustomizev1.ScheduleWindow{{
+				Cron:     "0 0 31 2 *",
+				Duration: metav1.Duration{Duration: time.Hour},
+			}}},
+			time:   time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC),
+			limit:  4 * 365 * 24 * time.Hour,
+			wantOk: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			got, ok, err := NextOpen(tt.schedule, tt.time, tt.limit)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(ok).To(Equal(tt.wantOk))
+			if tt.wantOk {
+				g.Expect(got.Equal(tt.want)).To(BeTrue(), "got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
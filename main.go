@@ -19,6 +19,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"time"
 
 	flag "github.com/spf13/pflag"
@@ -52,8 +53,10 @@ import (
 	sourcev1b2 "github.com/fluxcd/source-controller/api/v1beta2"
 
 	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1"
+	"github.com/fluxcd/kustomize-controller/internal/artifact"
 	"github.com/fluxcd/kustomize-controller/internal/controller"
 	"github.com/fluxcd/kustomize-controller/internal/features"
+	kmetrics "github.com/fluxcd/kustomize-controller/internal/metrics"
 	"github.com/fluxcd/kustomize-controller/internal/statusreaders"
 	// +kubebuilder:scaffold:imports
 )
@@ -82,6 +85,12 @@ func main() {
 		concurrent              int
 		concurrentSSA           int
 		requeueDependency       time.Duration
+		gracefulShutdownTimeout time.Duration
+		maxConsecutiveFailures  int
+		maxFailureRetryDelay    time.Duration
+		artifactCacheSize       int
+		artifactMaxDownloadSize int
+		artifactMaxUntarSize    int
 		clientOptions           runtimeClient.Options
 		kubeConfigOpts          runtimeClient.KubeConfigOptions
 		logOptions              logger.Options
@@ -91,10 +100,13 @@ func main() {
 		intervalJitterOptions   jitter.IntervalOptions
 		aclOptions              acl.Options
 		noRemoteBases           bool
+		noCrossNamespaceDeps    bool
 		httpRetry               int
 		defaultServiceAccount   string
+		sopsKMSOnly             bool
 		featureGates            feathelper.FeatureGates
 		disallowedFieldManagers []string
+		fieldManager            string
 	)
 
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
@@ -103,11 +115,28 @@ func main() {
 	flag.IntVar(&concurrent, "concurrent", 4, "The number of concurrent kustomize reconciles.")
 	flag.IntVar(&concurrentSSA, "concurrent-ssa", 4, "The number of concurrent server-side apply operations.")
 	flag.DurationVar(&requeueDependency, "requeue-dependency", 30*time.Second, "The interval at which failing dependencies are reevaluated.")
+	flag.DurationVar(&gracefulShutdownTimeout, "graceful-shutdown-timeout", 30*time.Second,
+		"The duration given to the in-flight reconciliations to abort and record their status on shutdown. A negative value waits indefinitely.")
+	flag.BoolVar(&noCrossNamespaceDeps, "no-cross-namespace-dependencies", false,
+		"Disallow Kustomizations from depending on Kustomizations in other namespaces.")
 	flag.BoolVar(&noRemoteBases, "no-remote-bases", false,
 		"Disallow remote bases usage in Kustomize overlays. When this flag is enabled, all resources must refer to local files included in the source artifact.")
 	flag.IntVar(&httpRetry, "http-retry", 9, "The maximum number of retries when failing to fetch artifacts over HTTP.")
+	flag.DurationVar(&maxFailureRetryDelay, "max-failure-retry-delay", 0,
+		"The maximum delay between the retries of a failing Kustomization. The delay starts at the retry interval of the Kustomization and doubles with each consecutive failure. Zero disables the backoff.")
+	flag.IntVar(&maxConsecutiveFailures, "max-consecutive-failures", 0,
+		"The number of consecutive failed reconciliations after which a Kustomization is marked as stalled and retried at its interval. Zero disables the limit.")
+	flag.IntVar(&artifactCacheSize, "artifact-cache-size", 0,
+		"The maximum number of source artifacts kept extracted on disk and shared between the Kustomizations referring to the same revision. Zero disables the cache.")
+	flag.IntVar(&artifactMaxDownloadSize, "artifact-max-download-size", 0,
+		"The maximum size in bytes of the downloaded source artifacts. Zero disables the limit.")
+	flag.IntVar(&artifactMaxUntarSize, "artifact-max-untar-size", 0,
+		"The maximum size in bytes of the files extracted from the source artifacts. Zero disables the limit.")
 	flag.StringVar(&defaultServiceAccount, "default-service-account", "", "Default service account used for impersonation.")
+	flag.BoolVar(&sopsKMSOnly, "sops-kms-only", false,
+		"Restrict SOPS decryption to AWS KMS, Azure Key Vault, GCP KMS and Hashicorp Vault keys. When this flag is enabled, PGP and age keys are not allowed.")
 	flag.StringArrayVar(&disallowedFieldManagers, "override-manager", []string{}, "Field manager disallowed to perform changes on managed resources.")
+	flag.StringVar(&fieldManager, "field-manager", controllerName, "The field manager used for the server-side apply of the managed resources.")
 
 	clientOptions.BindFlags(flag.CommandLine)
 	logOptions.BindFlags(flag.CommandLine)
@@ -171,6 +200,7 @@ func main() {
 		RenewDeadline:                 &leaderElectionOptions.RenewDeadline,
 		RetryPeriod:                   &leaderElectionOptions.RetryPeriod,
 		LeaderElectionID:              leaderElectionId,
+		GracefulShutdownTimeout:       &gracefulShutdownTimeout,
 		Logger:                        ctrl.Log,
 		Client: ctrlclient.Options{
 			Cache: &ctrlclient.CacheOptions{
@@ -234,25 +264,51 @@ func main() {
 		os.Exit(1)
 	}
 
+	detectOwnershipConflicts, err := features.Enabled(features.DetectOwnershipConflicts)
+	if err != nil {
+		setupLog.Error(err, "unable to check feature gate "+features.DetectOwnershipConflicts)
+		os.Exit(1)
+	}
+
+	var artifactCache *artifact.Cache
+	if artifactCacheSize > 0 {
+		artifactCache, err = artifact.NewCache(filepath.Join(os.TempDir(), "artifact-cache"), artifactCacheSize)
+		if err != nil {
+			setupLog.Error(err, "unable to create artifact cache")
+			os.Exit(1)
+		}
+	}
+
 	if err = (&controller.KustomizationReconciler{
-		ControllerName:          controllerName,
-		DefaultServiceAccount:   defaultServiceAccount,
-		Client:                  mgr.GetClient(),
-		Metrics:                 metricsH,
-		EventRecorder:           eventRecorder,
-		NoCrossNamespaceRefs:    aclOptions.NoCrossNamespaceRefs,
-		NoRemoteBases:           noRemoteBases,
-		FailFast:                failFast,
-		ConcurrentSSA:           concurrentSSA,
-		KubeConfigOpts:          kubeConfigOpts,
-		PollingOpts:             pollingOpts,
-		StatusPoller:            polling.NewStatusPoller(mgr.GetClient(), mgr.GetRESTMapper(), pollingOpts),
-		DisallowedFieldManagers: disallowedFieldManagers,
-		StrictSubstitutions:     strictSubstitutions,
+		ControllerName:           controllerName,
+		FieldManager:             fieldManager,
+		DefaultServiceAccount:    defaultServiceAccount,
+		Client:                   mgr.GetClient(),
+		APIReader:                mgr.GetAPIReader(),
+		Metrics:                  metricsH,
+		PhaseMetrics:             kmetrics.MustMakeRecorder(),
+		ArtifactCache:            artifactCache,
+		EventRecorder:            eventRecorder,
+		NoCrossNamespaceRefs:     aclOptions.NoCrossNamespaceRefs,
+		NoRemoteBases:            noRemoteBases,
+		NoCrossNamespaceDeps:     noCrossNamespaceDeps,
+		FailFast:                 failFast,
+		ConcurrentSSA:            concurrentSSA,
+		KubeConfigOpts:           kubeConfigOpts,
+		PollingOpts:              pollingOpts,
+		StatusPoller:             polling.NewStatusPoller(mgr.GetClient(), mgr.GetRESTMapper(), pollingOpts),
+		DisallowedFieldManagers:  disallowedFieldManagers,
+		StrictSubstitutions:      strictSubstitutions,
+		KMSOnlyDecryption:        sopsKMSOnly,
+		DetectOwnershipConflicts: detectOwnershipConflicts,
 	}).SetupWithManager(ctx, mgr, controller.KustomizationReconcilerOptions{
 		DependencyRequeueInterval: requeueDependency,
 		HTTPRetry:                 httpRetry,
 		RateLimiter:               runtimeCtrl.GetRateLimiter(rateLimiterOptions),
+		MaxFailureRetryDelay:      maxFailureRetryDelay,
+		MaxConsecutiveFailures:    maxConsecutiveFailures,
+		ArtifactMaxDownloadSize:   artifactMaxDownloadSize,
+		ArtifactMaxUntarSize:      artifactMaxUntarSize,
 	}); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", controllerName)
 		os.Exit(1)